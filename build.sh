@@ -3,6 +3,8 @@ set -euo pipefail
 
 SCRIPT_DIR="$(cd -- "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
 SOURCE_DIR="$SCRIPT_DIR/source"
+VERSION="${VERSION:-$(git -C "$SCRIPT_DIR" describe --tags --always --dirty 2>/dev/null || echo dev)}"
+LDFLAGS="-X geoget/install.Version=$VERSION"
 
 build() {
   local os="$1"
@@ -12,9 +14,9 @@ build() {
 
   echo "Building ${output} (${os}/${arch}${goarm:+/v${goarm}})"
   if [[ -n "$goarm" ]]; then
-    (cd "$SOURCE_DIR" && GOOS="$os" GOARCH="$arch" GOARM="$goarm" CGO_ENABLED=0 go build -o "$SCRIPT_DIR/$output" ./...)
+    (cd "$SOURCE_DIR" && GOOS="$os" GOARCH="$arch" GOARM="$goarm" CGO_ENABLED=0 go build -ldflags "$LDFLAGS" -o "$SCRIPT_DIR/$output" .)
   else
-    (cd "$SOURCE_DIR" && GOOS="$os" GOARCH="$arch" CGO_ENABLED=0 go build -o "$SCRIPT_DIR/$output" ./...)
+    (cd "$SOURCE_DIR" && GOOS="$os" GOARCH="$arch" CGO_ENABLED=0 go build -ldflags "$LDFLAGS" -o "$SCRIPT_DIR/$output" .)
   fi
 }
 
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"geoget/install"
+)
+
+const (
+	bisectStateName   = "bisect.json"
+	bisectInstanceDir = "instance"
+
+	verdictGood = "good"
+	verdictBad  = "bad"
+	verdictSkip = "skip"
+)
+
+// bisectState is a bisect in progress, saved in the scratch root after
+// every verdict so that a later run picks up where this one stopped.
+// Candidates run from the good release to the bad one, oldest first.
+type bisectState struct {
+	Good       string            `json:"good"`
+	Bad        string            `json:"bad"`
+	Lang       string            `json:"lang,omitempty"`
+	Candidates []string          `json:"candidates"`
+	Verdicts   map[string]string `json:"verdicts"`
+}
+
+func runBisectCommand(ctx context.Context, args []string) error {
+	var good, bad, lang string
+	var reset bool
+	var httpOpts install.HTTPOptions
+
+	flags := flag.NewFlagSet("bisect", flag.ContinueOnError)
+	flags.StringVar(&good, "good", "", "GEOS release tag or issue number known to work")
+	flags.StringVar(&bad, "bad", "", "GEOS release tag or issue number known to be broken")
+	flags.StringVar(&lang, "lang", "", "GEOS language to test (e.g., gr)")
+	flags.BoolVar(&reset, "reset", false, "discard a bisect in progress")
+	addHTTPFlags(flags, &httpOpts)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return errors.New("usage: bisect --good <tag> --bad <tag> [--lang gr] [--reset] [scratch_root]")
+	}
+	if strings.Contains(lang, ",") {
+		return errors.New("bisect tests a single language")
+	}
+	var err error
+	if good != "" {
+		if good, err = releaseTag(good, "GEOS"); err != nil {
+			return fmt.Errorf("--good: %w", err)
+		}
+	}
+	if bad != "" {
+		if bad, err = releaseTag(bad, "GEOS"); err != nil {
+			return fmt.Errorf("--bad: %w", err)
+		}
+	}
+
+	config, err := loadUserConfig()
+	if err != nil {
+		return err
+	}
+	httpOpts = httpOptions(httpOpts, config)
+
+	root := flags.Arg(0)
+	if root == "" {
+		root = "geospc-bisect"
+	}
+	scratchRoot, err := resolveInstallRoot(root)
+	if err != nil {
+		return err
+	}
+
+	statePath := filepath.Join(scratchRoot, bisectStateName)
+
+	if reset {
+		if err := os.Remove(statePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("reset bisect: %w", err)
+		}
+		if good == "" && bad == "" {
+			fmt.Println("Bisect reset")
+			return nil
+		}
+	}
+
+	state, err := loadBisectState(statePath)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case state == nil:
+		if good == "" || bad == "" {
+			return errors.New("start a bisect with --good <tag> and --bad <tag>")
+		}
+		if state, err = newBisectState(ctx, good, bad, lang, httpOpts); err != nil {
+			return err
+		}
+		if err := saveBisectState(statePath, state); err != nil {
+			return err
+		}
+	case good != "" && good != state.Good || bad != "" && bad != state.Bad:
+		return fmt.Errorf("a bisect of %s..%s is in progress in %s; use --reset to start over", state.Good, state.Bad, scratchRoot)
+	default:
+		fmt.Printf("Resuming bisect of %s..%s\n", state.Good, state.Bad)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	instanceRoot := filepath.Join(scratchRoot, bisectInstanceDir)
+
+	for {
+		next, left := state.next()
+		if next < 0 {
+			state.report()
+			return nil
+		}
+
+		tag := state.Candidates[next]
+		fmt.Printf("Bisecting: %d build(s) left to test, trying %s\n", left, tag)
+
+		verdict, err := testBisectCandidate(ctx, reader, instanceRoot, tag, state.Lang, httpOpts)
+		if err != nil {
+			return err
+		}
+		if verdict == "" {
+			fmt.Println("Bisect paused, run the same bisect command again to continue")
+			return nil
+		}
+
+		state.Verdicts[tag] = verdict
+		if err := saveBisectState(statePath, state); err != nil {
+			return err
+		}
+	}
+}
+
+// newBisectState lists the GEOS releases published between good and bad.
+func newBisectState(ctx context.Context, good, bad, lang string, httpOpts install.HTTPOptions) (*bisectState, error) {
+	releases, err := install.GeosReleases(ctx, httpOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	goodIndex, badIndex := -1, -1
+	for i, release := range releases {
+		switch release.Tag {
+		case good:
+			goodIndex = i
+		case bad:
+			badIndex = i
+		}
+	}
+
+	if goodIndex < 0 {
+		return nil, fmt.Errorf("GEOS release %s not found", good)
+	}
+	if badIndex < 0 {
+		return nil, fmt.Errorf("GEOS release %s not found", bad)
+	}
+	if goodIndex >= badIndex {
+		return nil, fmt.Errorf("GEOS release %s was not published before %s", good, bad)
+	}
+
+	state := &bisectState{
+		Good:     good,
+		Bad:      bad,
+		Lang:     lang,
+		Verdicts: map[string]string{good: verdictGood, bad: verdictBad},
+	}
+	for _, release := range releases[goodIndex : badIndex+1] {
+		state.Candidates = append(state.Candidates, release.Tag)
+	}
+
+	return state, nil
+}
+
+// bounds returns the index of the newest good candidate and of the oldest
+// bad one after it.
+func (s *bisectState) bounds() (int, int) {
+	last := len(s.Candidates) - 1
+
+	bad := last
+	for i, tag := range s.Candidates {
+		if s.Verdicts[tag] == verdictBad {
+			bad = i
+			break
+		}
+	}
+
+	good := 0
+	for i := bad - 1; i > 0; i-- {
+		if s.Verdicts[s.Candidates[i]] == verdictGood {
+			good = i
+			break
+		}
+	}
+
+	return good, bad
+}
+
+// next picks the untested candidate closest to the middle of the remaining
+// range and reports how many are left; it returns -1 once the range holds
+// nothing but skipped builds.
+func (s *bisectState) next() (int, int) {
+	good, bad := s.bounds()
+	middle := (good + bad) / 2
+
+	best, left := -1, 0
+	for i := good + 1; i < bad; i++ {
+		if s.Verdicts[s.Candidates[i]] != "" {
+			continue
+		}
+		left++
+		if best < 0 || abs(i-middle) < abs(best-middle) {
+			best = i
+		}
+	}
+
+	return best, left
+}
+
+func (s *bisectState) report() {
+	good, bad := s.bounds()
+
+	skipped := s.Candidates[good+1 : bad]
+	if len(skipped) == 0 {
+		fmt.Printf("First bad build: %s (last good: %s)\n", s.Candidates[bad], s.Candidates[good])
+		return
+	}
+
+	fmt.Printf("The first bad build is one of: %s, %s (last good: %s)\n",
+		strings.Join(skipped, ", "), s.Candidates[bad], s.Candidates[good])
+}
+
+// testBisectCandidate installs tag into the scratch instance, starts it
+// and asks for a verdict. An empty verdict means the user quit.
+func testBisectCandidate(ctx context.Context, reader *bufio.Reader, instanceRoot, tag, lang string, httpOpts install.HTTPOptions) (string, error) {
+	config, err := loadUserConfig()
+	if err != nil {
+		return "", err
+	}
+
+	installer, err := install.New(install.Options{
+		Root:           instanceRoot,
+		Force:          true,
+		GeosTag:        tag,
+		Lang:           lang,
+		CacheDownloads: true,
+		Mirrors:        mirrorList(nil, config),
+		HTTP:           httpOpts,
+		Reporter:       log.New(os.Stdout, "[geoget] ", 0),
+		Progress:       os.Stdout,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := installer.Install(ctx); err != nil {
+		if ctx.Err() != nil {
+			return "", err
+		}
+		fmt.Fprintf(os.Stderr, "Warning: could not install %s, skipping it: %v\n", tag, err)
+		return verdictSkip, nil
+	}
+
+	fmt.Println("Starting Ensemble, close Basebox when done testing")
+	if err := installer.Launch(ctx, ""); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	for {
+		fmt.Printf("Is %s good or bad? [good/bad/skip/quit]: ", tag)
+
+		input, err := readLine(ctx, reader)
+		if err != nil {
+			return "", fmt.Errorf("read verdict: %w", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "g", verdictGood:
+			return verdictGood, nil
+		case "b", verdictBad:
+			return verdictBad, nil
+		case "s", verdictSkip:
+			return verdictSkip, nil
+		case "q", "quit":
+			return "", nil
+		}
+	}
+}
+
+func loadBisectState(path string) (*bisectState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read bisect state: %w", err)
+	}
+
+	var state bisectState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parse bisect state: %w", err)
+	}
+	if len(state.Candidates) < 2 {
+		return nil, fmt.Errorf("parse bisect state: no candidates in %s", path)
+	}
+	if state.Verdicts == nil {
+		state.Verdicts = make(map[string]string)
+	}
+
+	return &state, nil
+}
+
+func saveBisectState(path string, state *bisectState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode bisect state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create scratch root: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write bisect state: %w", err)
+	}
+
+	return nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
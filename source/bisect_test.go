@@ -0,0 +1,86 @@
+package main
+
+import "testing"
+
+func TestBisectBounds(t *testing.T) {
+	candidates := []string{"r0", "r1", "r2", "r3", "r4", "r5", "r6"}
+
+	tests := []struct {
+		name      string
+		verdicts  map[string]string
+		good, bad int
+		next      int
+		left      int
+	}{
+		{
+			name:     "start",
+			verdicts: map[string]string{"r0": verdictGood, "r6": verdictBad},
+			good:     0, bad: 6, next: 3, left: 5,
+		},
+		{
+			name:     "good in the middle",
+			verdicts: map[string]string{"r0": verdictGood, "r3": verdictGood, "r6": verdictBad},
+			good:     3, bad: 6, next: 4, left: 2,
+		},
+		{
+			name:     "bad in the middle",
+			verdicts: map[string]string{"r0": verdictGood, "r3": verdictBad, "r6": verdictBad},
+			good:     0, bad: 3, next: 1, left: 2,
+		},
+		{
+			name:     "skip moves to the next closest",
+			verdicts: map[string]string{"r0": verdictGood, "r3": verdictSkip, "r6": verdictBad},
+			good:     0, bad: 6, next: 2, left: 4,
+		},
+		{
+			name:     "good after bad is ignored",
+			verdicts: map[string]string{"r0": verdictGood, "r2": verdictBad, "r4": verdictGood, "r6": verdictBad},
+			good:     0, bad: 2, next: 1, left: 1,
+		},
+		{
+			name:     "found",
+			verdicts: map[string]string{"r0": verdictGood, "r4": verdictGood, "r5": verdictBad, "r6": verdictBad},
+			good:     4, bad: 5, next: -1, left: 0,
+		},
+		{
+			name:     "only skipped left",
+			verdicts: map[string]string{"r0": verdictGood, "r4": verdictGood, "r5": verdictSkip, "r6": verdictBad},
+			good:     4, bad: 6, next: -1, left: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &bisectState{Candidates: candidates, Verdicts: tt.verdicts}
+
+			if good, bad := s.bounds(); good != tt.good || bad != tt.bad {
+				t.Errorf("bounds() = %d, %d; want %d, %d", good, bad, tt.good, tt.bad)
+			}
+			if next, left := s.next(); next != tt.next || left != tt.left {
+				t.Errorf("next() = %d, %d; want %d, %d", next, left, tt.next, tt.left)
+			}
+		})
+	}
+}
+
+func TestReleaseTag(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"801", "CI-latest-issue-801", false},
+		{"#829", "CI-latest-issue-829", false},
+		{" 12 ", "CI-latest-issue-12", false},
+		{"CI-latest", "CI-latest", false},
+		{"CI-latest-issue-801", "CI-latest-issue-801", false},
+		{"#", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := releaseTag(tt.value, "GEOS")
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("releaseTag(%q) = %q, %v; want %q", tt.value, got, err, tt.want)
+		}
+	}
+}
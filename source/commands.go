@@ -0,0 +1,11 @@
+package main
+
+import "context"
+
+var subcommands = map[string]func(ctx context.Context, args []string) error{
+	"bisect": runBisectCommand,
+	"config": runConfigCommand,
+	"ini":    runIniCommand,
+	"mirror": runMirrorCommand,
+	"sync":   runSyncCommand,
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"geoget/install"
+)
+
+func runConfigCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: config get <section.key> [install_root] | config set <section.key> <value> [install_root]")
+	}
+
+	switch args[0] {
+	case "get":
+		if len(args) < 2 || len(args) > 3 {
+			return errors.New("usage: config get <section.key> [install_root]")
+		}
+		return configGet(args[1], optionalArg(args, 2))
+	case "set":
+		if len(args) < 3 || len(args) > 4 {
+			return errors.New("usage: config set <section.key> <value> [install_root]")
+		}
+		return configSet(args[1], args[2], optionalArg(args, 3))
+	default:
+		return fmt.Errorf("unknown config command %q", args[0])
+	}
+}
+
+func configGet(ref, rootArg string) error {
+	installer, err := openInstaller(rootArg)
+	if err != nil {
+		return err
+	}
+
+	value, err := installer.Config(ref)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(value)
+	return nil
+}
+
+func configSet(ref, value, rootArg string) error {
+	installer, err := openInstaller(rootArg)
+	if err != nil {
+		return err
+	}
+
+	return installer.SetConfig(ref, value)
+}
+
+// openInstaller returns an Installer for the existing install in rootArg.
+func openInstaller(rootArg string) (*install.Installer, error) {
+	installRoot, err := resolveInstallRoot(rootArg)
+	if err != nil {
+		return nil, err
+	}
+
+	return install.New(install.Options{Root: installRoot})
+}
+
+func optionalArg(args []string, index int) string {
+	if index < len(args) {
+		return args[index]
+	}
+	return ""
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+
+	"geoget/install"
+)
+
+// addHTTPFlags registers the network options of the commands that
+// download.
+func addHTTPFlags(flags *flag.FlagSet, opts *install.HTTPOptions) {
+	flags.StringVar(&opts.Proxy, "proxy", "", "HTTP proxy URL (default: HTTPS_PROXY, or \"proxy\" in config.json)")
+	flags.StringVar(&opts.CACert, "ca-cert", "", "PEM file with additional trusted CA certificates")
+	flags.StringVar(&opts.Token, "token", "", "GitHub token for API requests (default: GITHUB_TOKEN)")
+}
+
+// httpOptions completes the network options from the flags with the user
+// config.
+func httpOptions(opts install.HTTPOptions, config *userConfig) install.HTTPOptions {
+	if opts.Proxy == "" {
+		opts.Proxy = config.Proxy
+	}
+	if opts.CACert == "" {
+		opts.CACert = config.CACert
+	}
+	return opts
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const iniUsage = "usage: ini get <category> <key> [install_root] | ini set <category> <key> <value> [install_root] | ini unset <category> <key> [install_root]"
+
+func runIniCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(iniUsage)
+	}
+
+	switch args[0] {
+	case "get":
+		if len(args) < 3 || len(args) > 4 {
+			return errors.New("usage: ini get <category> <key> [install_root]")
+		}
+		return iniGet(args[1], args[2], optionalArg(args, 3))
+	case "set":
+		if len(args) < 4 || len(args) > 5 {
+			return errors.New("usage: ini set <category> <key> <value> [install_root]")
+		}
+		return iniSet(args[1], args[2], args[3], optionalArg(args, 4))
+	case "unset":
+		if len(args) < 3 || len(args) > 4 {
+			return errors.New("usage: ini unset <category> <key> [install_root]")
+		}
+		return iniUnset(args[1], args[2], optionalArg(args, 3))
+	default:
+		return fmt.Errorf("unknown ini command %q", args[0])
+	}
+}
+
+func iniGet(category, key, rootArg string) error {
+	installer, err := openInstaller(rootArg)
+	if err != nil {
+		return err
+	}
+
+	values, err := installer.Ini(category, key)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range values {
+		if len(values) > 1 {
+			fmt.Printf("%s: %s\n", v.Lang, v.Value)
+		} else {
+			fmt.Println(v.Value)
+		}
+	}
+
+	return nil
+}
+
+func iniSet(category, key, value, rootArg string) error {
+	installer, err := openInstaller(rootArg)
+	if err != nil {
+		return err
+	}
+
+	return installer.SetIni(category, key, value)
+}
+
+func iniUnset(category, key, rootArg string) error {
+	installer, err := openInstaller(rootArg)
+	if err != nil {
+		return err
+	}
+
+	return installer.UnsetIni(category, key)
+}
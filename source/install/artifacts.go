@@ -0,0 +1,226 @@
+package install
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// artifactBuild is a successful GitHub Actions run whose artifacts stand
+// in for a release, e.g. the CI build of a pull request.
+type artifactBuild struct {
+	repo      string
+	label     string
+	sha       string
+	runID     int64
+	artifacts []githubArtifact
+}
+
+type githubArtifact struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	SizeInBytes        int64  `json:"size_in_bytes"`
+	ArchiveDownloadURL string `json:"archive_download_url"`
+	Expired            bool   `json:"expired"`
+}
+
+type githubWorkflowRun struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	HeadSHA    string `json:"head_sha"`
+	Conclusion string `json:"conclusion"`
+}
+
+// resolveArtifactBuild finds the newest successful workflow run for a pull
+// request number or a commit/branch ref with an artifact whose name starts
+// like asset, e.g. "pcgeos-ensemble_" for any language. Runs of other
+// workflows, such as docs or lint, are skipped.
+func resolveArtifactBuild(ctx context.Context, repo, pr, ref, asset string) (*artifactBuild, error) {
+	var sha, label string
+
+	switch {
+	case pr != "":
+		number := strings.TrimPrefix(strings.TrimSpace(pr), "#")
+		if !isNumeric(number) {
+			return nil, fmt.Errorf("pull request number must be numeric: %q", pr)
+		}
+
+		var pull struct {
+			Head struct {
+				SHA string `json:"sha"`
+			} `json:"head"`
+		}
+		if err := getJSON(ctx, fmt.Sprintf("%s/repos/%s/pulls/%s", githubAPIBaseURL, repo, number), &pull); err != nil {
+			return nil, fmt.Errorf("look up pull request #%s of %s: %w", number, repo, err)
+		}
+		sha = pull.Head.SHA
+		label = "pr-" + number
+
+	case ref != "":
+		var commit struct {
+			SHA string `json:"sha"`
+		}
+		if err := getJSON(ctx, fmt.Sprintf("%s/repos/%s/commits/%s", githubAPIBaseURL, repo, url.PathEscape(ref)), &commit); err != nil {
+			return nil, fmt.Errorf("look up %s of %s: %w", ref, repo, err)
+		}
+		sha = commit.SHA
+		// Branches such as feature/x keep their slash escaped, so the
+		// label stays one path element and buildFromLabel gets it back.
+		label = "ref-" + url.PathEscape(ref)
+
+	default:
+		return nil, errors.New("no pull request or ref given")
+	}
+
+	var runs struct {
+		WorkflowRuns []githubWorkflowRun `json:"workflow_runs"`
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/actions/runs?head_sha=%s&status=success&per_page=20", githubAPIBaseURL, repo, sha)
+	if err := getJSON(ctx, endpoint, &runs); err != nil {
+		return nil, fmt.Errorf("list workflow runs of %s: %w", repo, err)
+	}
+
+	for _, run := range runs.WorkflowRuns {
+		var list struct {
+			Artifacts []githubArtifact `json:"artifacts"`
+		}
+		endpoint := fmt.Sprintf("%s/repos/%s/actions/runs/%d/artifacts", githubAPIBaseURL, repo, run.ID)
+		if err := getJSON(ctx, endpoint, &list); err != nil {
+			return nil, fmt.Errorf("list artifacts of run %d: %w", run.ID, err)
+		}
+
+		var artifacts []githubArtifact
+		for _, artifact := range list.Artifacts {
+			if !artifact.Expired {
+				artifacts = append(artifacts, artifact)
+			}
+		}
+
+		for _, artifact := range artifacts {
+			if artifactMatches(artifact.Name, asset) {
+				return &artifactBuild{
+					repo:      repo,
+					label:     fmt.Sprintf("%s@%s", label, shortSHA(sha)),
+					sha:       sha,
+					runID:     run.ID,
+					artifacts: artifacts,
+				}, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no successful workflow run with an artifact for %s of %s at %s", asset, repo, shortSHA(sha))
+}
+
+// artifactMatches reports whether an artifact name, with or without the
+// .zip suffix, starts like asset.
+func artifactMatches(name, asset string) bool {
+	return strings.HasPrefix(strings.ToLower(name), strings.ToLower(strings.TrimSuffix(asset, ".zip")))
+}
+
+// artifactFor picks the artifact carrying asset, matching its name with or
+// without the .zip suffix; a lone artifact is taken as is.
+func (b *artifactBuild) artifactFor(asset string) (githubArtifact, error) {
+	base := strings.TrimSuffix(asset, ".zip")
+	for _, artifact := range b.artifacts {
+		if strings.EqualFold(artifact.Name, asset) || strings.EqualFold(artifact.Name, base) {
+			return artifact, nil
+		}
+	}
+
+	if len(b.artifacts) == 1 {
+		return b.artifacts[0], nil
+	}
+
+	return githubArtifact{}, fmt.Errorf("run %d of %s has no artifact for %s", b.runID, b.repo, asset)
+}
+
+// assetNames lists the artifact names as release asset names.
+func (b *artifactBuild) assetNames() []string {
+	var names []string
+	for _, artifact := range b.artifacts {
+		name := artifact.Name
+		if !strings.HasSuffix(name, ".zip") {
+			name += ".zip"
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// downloadArtifact fetches the artifact holding asset to destination.
+// GitHub wraps artifacts in a zip of their own; if that wrapper contains
+// the release zip, the inner zip is unpacked, otherwise the wrapper itself
+// already is the archive.
+func (b *artifactBuild) downloadArtifact(ctx context.Context, asset, destination string, dl downloadOptions, limits ExtractLimits) error {
+	artifact, err := b.artifactFor(asset)
+	if err != nil {
+		return err
+	}
+
+	wrapper := destination + ".artifact"
+	defer os.Remove(wrapper)
+
+	if err := downloadFile(ctx, artifact.ArchiveDownloadURL, wrapper, dl); err != nil {
+		return err
+	}
+
+	found, err := extractInnerZip(wrapper, asset, destination, limits)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return os.Rename(wrapper, destination)
+	}
+	return nil
+}
+
+// extractInnerZip copies the entry named asset out of archivePath, within
+// the same limits as any other extraction.
+func extractInnerZip(archivePath, asset, destination string, limits ExtractLimits) (bool, error) {
+	limits = limits.withDefaults()
+
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return false, fmt.Errorf("open artifact: %w", err)
+	}
+	defer reader.Close()
+
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() || !strings.EqualFold(path.Base(f.Name), asset) {
+			continue
+		}
+
+		entry := zipEntry{file: f, target: destination}
+		size, err := checkZipHeaders([]zipEntry{entry}, limits)
+		if err != nil {
+			return false, err
+		}
+		if err := checkFreeSpace(archivePath, filepath.Dir(destination), size); err != nil {
+			return false, err
+		}
+
+		var budget atomic.Int64
+		budget.Store(limits.MaxBytes)
+		if err := extractZipFile(entry, limits, &budget); err != nil {
+			return false, fmt.Errorf("unpack %s: %w", f.Name, err)
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
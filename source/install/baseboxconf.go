@@ -0,0 +1,435 @@
+package install
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	baseboxConfigName     = "basebox.conf"
+	baseboxUserConfigName = "basebox.user.conf"
+	autoexecSection       = "autoexec"
+)
+
+type baseboxConfig struct {
+	sections []*baseboxSection
+}
+
+type baseboxSection struct {
+	name    string
+	entries []baseboxEntry
+	// lines holds the verbatim body of [autoexec], which is a batch
+	// script rather than key=value pairs.
+	lines []string
+}
+
+type baseboxEntry struct {
+	key   string
+	value string
+	raw   string
+}
+
+// baseboxSettings collects what the generator applies on top of the template.
+type baseboxSettings struct {
+	preset     Preset
+	mounts     []Mount
+	keyboard   keyboardLayout
+	resolution string
+}
+
+func settingsFor(binary baseboxBinary, manifest *Manifest, lang string) (baseboxSettings, error) {
+	settings := baseboxSettings{preset: binary.preset}
+	if manifest == nil {
+		manifest = &Manifest{}
+	}
+
+	settings.preset = settings.preset.overlay(manifest.Basebox)
+	settings.mounts = manifest.Mounts
+	settings.resolution = manifest.Resolution
+
+	keyboard, err := resolveKeyboard(manifest.Keyboard, lang)
+	if err != nil {
+		return baseboxSettings{}, err
+	}
+	settings.keyboard = keyboard
+
+	return settings, nil
+}
+
+func writeBaseboxConfig(baseboxDir, drivecDir, configName string, settings baseboxSettings) error {
+	if err := settings.preset.validate(); err != nil {
+		return err
+	}
+
+	config, err := generateBaseboxConfig(drivecDir)
+	if err != nil {
+		return err
+	}
+
+	settings.preset.apply(config)
+	applyResolution(config, settings.resolution, settings.preset)
+	applyMounts(config, settings.mounts)
+	applyKeyboard(config, settings.keyboard)
+
+	overlay, err := loadBaseboxConfig(filepath.Join(baseboxDir, baseboxUserConfigName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if overlay != nil {
+		config.merge(overlay)
+	}
+
+	dest := filepath.Join(baseboxDir, configName)
+	if err := os.WriteFile(dest, []byte(config.String()), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", configName, err)
+	}
+
+	return nil
+}
+
+// regenerateBaseboxConfig rewrites the basebox.conf files of an existing
+// install from its manifest and user overlay.
+func regenerateBaseboxConfig(installRoot string) error {
+	baseboxDir := filepath.Join(installRoot, "basebox")
+
+	binary, err := detectBaseboxBinary(baseboxDir)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := loadManifest(installRoot)
+	if err != nil {
+		return err
+	}
+
+	installs, err := installedLanguages(installRoot)
+	if err != nil {
+		return err
+	}
+
+	for _, li := range installs {
+		settings, err := settingsFor(binary, manifest, li.lang)
+		if err != nil {
+			return err
+		}
+
+		if err := writeBaseboxConfig(baseboxDir, li.drivecDir, li.configName, settings); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Config returns a setting of the generated Basebox config, named
+// "section.key".
+func (i *Installer) Config(ref string) (string, error) {
+	section, key, err := parseConfigKey(ref)
+	if err != nil {
+		return "", err
+	}
+
+	installs, err := i.installed()
+	if err != nil {
+		return "", err
+	}
+
+	config, err := loadBaseboxConfig(filepath.Join(i.opts.Root, "basebox", installs[0].configName))
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := config.get(section, key)
+	if !ok {
+		return "", fmt.Errorf("%s is not set", ref)
+	}
+
+	return value, nil
+}
+
+// SetConfig records a Basebox setting in the user overlay, which survives
+// updates, and regenerates the Basebox configs.
+func (i *Installer) SetConfig(ref, value string) error {
+	section, key, err := parseConfigKey(ref)
+	if err != nil {
+		return err
+	}
+
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("Basebox config value must be a single line")
+	}
+
+	if _, err := i.installed(); err != nil {
+		return err
+	}
+
+	overlayPath := filepath.Join(i.opts.Root, "basebox", baseboxUserConfigName)
+	overlay, err := loadBaseboxConfig(overlayPath)
+	if errors.Is(err, os.ErrNotExist) {
+		overlay, err = &baseboxConfig{}, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	overlay.set(section, key, value)
+	if err := saveBaseboxConfig(overlayPath, overlay); err != nil {
+		return err
+	}
+
+	return regenerateBaseboxConfig(i.opts.Root)
+}
+
+func generateBaseboxConfig(drivecDir string) (*baseboxConfig, error) {
+	data, err := templateFS.ReadFile("templ/basebox.conf")
+	if err != nil {
+		return nil, fmt.Errorf("read basebox template: %w", err)
+	}
+
+	text := string(data)
+
+	loaderDir, err := resolveGeosLoaderDir(drivecDir)
+	if err == nil {
+		text = strings.ReplaceAll(text, "{{LOADER_DIR}}", loaderDir)
+	}
+
+	hostPath := filepath.Clean(drivecDir)
+	text = strings.ReplaceAll(text, "{{HOST_PATH}}", hostPath)
+
+	config, err := parseBaseboxConfig(strings.NewReader(text))
+	if err != nil {
+		return nil, fmt.Errorf("parse basebox template: %w", err)
+	}
+
+	return config, nil
+}
+
+func loadBaseboxConfig(path string) (*baseboxConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	config, err := parseBaseboxConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+func saveBaseboxConfig(path string, config *baseboxConfig) error {
+	if err := os.WriteFile(path, []byte(config.String()), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func parseBaseboxConfig(r io.Reader) (*baseboxConfig, error) {
+	config := &baseboxConfig{}
+	current := config.section("")
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if name, ok := parseSectionHeader(trimmed); ok {
+			current = config.section(name)
+			continue
+		}
+
+		if current.isAutoexec() {
+			current.lines = append(current.lines, line)
+			continue
+		}
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+			current.entries = append(current.entries, baseboxEntry{raw: line})
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key=value, got %q", lineNo, trimmed)
+		}
+
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("line %d: missing key", lineNo)
+		}
+
+		current.entries = append(current.entries, baseboxEntry{key: key, value: strings.TrimSpace(value)})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func parseSectionHeader(line string) (string, bool) {
+	if len(line) < 2 || line[0] != '[' || line[len(line)-1] != ']' {
+		return "", false
+	}
+	return strings.TrimSpace(line[1 : len(line)-1]), true
+}
+
+// parseConfigKey splits a "section.key" reference as used by the config command.
+func parseConfigKey(ref string) (string, string, error) {
+	section, key, ok := strings.Cut(strings.TrimSpace(ref), ".")
+	if !ok || section == "" || key == "" {
+		return "", "", fmt.Errorf("config key must look like section.key: %q", ref)
+	}
+
+	if strings.EqualFold(section, autoexecSection) {
+		return "", "", fmt.Errorf("[%s] has no keys; edit %s instead", autoexecSection, baseboxUserConfigName)
+	}
+
+	if strings.ContainsAny(section, "[]\r\n") || strings.ContainsAny(key, "=\r\n") {
+		return "", "", fmt.Errorf("invalid config key: %q", ref)
+	}
+
+	return section, key, nil
+}
+
+// section returns the named section, appending an empty one if needed.
+func (c *baseboxConfig) section(name string) *baseboxSection {
+	if s := c.lookup(name); s != nil {
+		return s
+	}
+
+	s := &baseboxSection{name: name}
+
+	// Keep [autoexec] last, as Basebox users expect.
+	for i, existing := range c.sections {
+		if existing.isAutoexec() && !s.isAutoexec() {
+			c.sections = append(c.sections[:i+1], c.sections[i:]...)
+			c.sections[i] = s
+			return s
+		}
+	}
+
+	c.sections = append(c.sections, s)
+	return s
+}
+
+func (c *baseboxConfig) lookup(name string) *baseboxSection {
+	for _, s := range c.sections {
+		if strings.EqualFold(s.name, name) {
+			return s
+		}
+	}
+	return nil
+}
+
+func (c *baseboxConfig) get(section, key string) (string, bool) {
+	s := c.lookup(section)
+	if s == nil {
+		return "", false
+	}
+	return s.get(key)
+}
+
+func (c *baseboxConfig) set(section, key, value string) {
+	c.section(section).set(key, value)
+}
+
+// merge applies the keys of overlay on top of c. A non-empty [autoexec]
+// in the overlay replaces the generated one as a whole.
+func (c *baseboxConfig) merge(overlay *baseboxConfig) {
+	for _, s := range overlay.sections {
+		if s.isAutoexec() {
+			if s.hasContent() {
+				c.section(s.name).lines = append([]string(nil), s.lines...)
+			}
+			continue
+		}
+
+		for _, entry := range s.entries {
+			if entry.key != "" {
+				c.set(s.name, entry.key, entry.value)
+			}
+		}
+	}
+}
+
+func (c *baseboxConfig) String() string {
+	var out []string
+
+	for _, s := range c.sections {
+		if s.name == "" && len(s.entries) == 0 {
+			continue
+		}
+
+		if s.name != "" {
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+			out = append(out, "["+s.name+"]")
+		}
+
+		for _, entry := range s.entries {
+			if entry.key == "" {
+				out = append(out, entry.raw)
+				continue
+			}
+			out = append(out, entry.key+"="+entry.value)
+		}
+
+		out = append(out, s.lines...)
+	}
+
+	return strings.Join(out, "\n") + "\n"
+}
+
+func (s *baseboxSection) isAutoexec() bool {
+	return strings.EqualFold(s.name, autoexecSection)
+}
+
+func (s *baseboxSection) hasContent() bool {
+	for _, line := range s.lines {
+		if strings.TrimSpace(line) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *baseboxSection) get(key string) (string, bool) {
+	for _, entry := range s.entries {
+		if entry.key != "" && strings.EqualFold(entry.key, key) {
+			return entry.value, true
+		}
+	}
+	return "", false
+}
+
+func (s *baseboxSection) set(key, value string) {
+	for i, entry := range s.entries {
+		if entry.key != "" && strings.EqualFold(entry.key, key) {
+			s.entries[i].value = value
+			return
+		}
+	}
+
+	// Insert after the last key so trailing blank lines keep separating
+	// this section from the next one.
+	pos := len(s.entries)
+	for pos > 0 && strings.TrimSpace(s.entries[pos-1].raw) == "" && s.entries[pos-1].key == "" {
+		pos--
+	}
+
+	s.entries = append(s.entries, baseboxEntry{})
+	copy(s.entries[pos+1:], s.entries[pos:])
+	s.entries[pos] = baseboxEntry{key: key, value: value}
+}
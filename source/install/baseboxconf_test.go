@@ -0,0 +1,157 @@
+package install
+
+import (
+	"strings"
+	"testing"
+)
+
+const testBaseboxConfig = `# generated
+
+[sdl]
+fullscreen=false
+output=opengl
+
+[cpu]
+cycles=max
+
+[autoexec]
+@echo off
+mount c "/geos" -t dir
+c:
+loader
+`
+
+func TestParseBaseboxConfig(t *testing.T) {
+	config, err := parseBaseboxConfig(strings.NewReader(testBaseboxConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := config.String(); got != testBaseboxConfig {
+		t.Errorf("round trip changed the config:\ngot  %q\nwant %q", got, testBaseboxConfig)
+	}
+
+	tests := []struct {
+		section, key string
+		want         string
+		ok           bool
+	}{
+		{"sdl", "output", "opengl", true},
+		{"SDL", "Output", "opengl", true},
+		{"cpu", "cycles", "max", true},
+		{"cpu", "core", "", false},
+		{"render", "glshader", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := config.get(tt.section, tt.key)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("get(%q, %q) = %q, %v; want %q, %v", tt.section, tt.key, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseBaseboxConfigErrors(t *testing.T) {
+	tests := []string{
+		"[sdl]\nfullscreen\n",
+		"[sdl]\n=true\n",
+	}
+	for _, data := range tests {
+		if _, err := parseBaseboxConfig(strings.NewReader(data)); err == nil {
+			t.Errorf("parseBaseboxConfig(%q) succeeded", data)
+		}
+	}
+}
+
+func TestBaseboxConfigMerge(t *testing.T) {
+	tests := []struct {
+		name    string
+		overlay string
+		want    map[[2]string]string
+		// autoexec is a line the merged [autoexec] must contain.
+		autoexec string
+	}{
+		{
+			name:     "keys replace and add",
+			overlay:  "[sdl]\noutput=texture\n[render]\nglshader=sharp\n",
+			want:     map[[2]string]string{{"sdl", "output"}: "texture", {"sdl", "fullscreen"}: "false", {"render", "glshader"}: "sharp"},
+			autoexec: "loader",
+		},
+		{
+			name:     "empty autoexec keeps the generated one",
+			overlay:  "[cpu]\ncycles=auto\n[autoexec]\n\n",
+			want:     map[[2]string]string{{"cpu", "cycles"}: "auto"},
+			autoexec: "loader",
+		},
+		{
+			name:     "autoexec replaces the generated one",
+			overlay:  "[autoexec]\nmount c /other\n",
+			autoexec: "mount c /other",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := parseBaseboxConfig(strings.NewReader(testBaseboxConfig))
+			if err != nil {
+				t.Fatal(err)
+			}
+			overlay, err := parseBaseboxConfig(strings.NewReader(tt.overlay))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config.merge(overlay)
+
+			for ref, want := range tt.want {
+				if got, _ := config.get(ref[0], ref[1]); got != want {
+					t.Errorf("%s.%s = %q, want %q", ref[0], ref[1], got, want)
+				}
+			}
+
+			autoexec := config.lookup(autoexecSection)
+			if autoexec == nil || !containsString(autoexec.lines, tt.autoexec) {
+				t.Errorf("[autoexec] lacks %q: %q", tt.autoexec, autoexec.lines)
+			}
+			if sections := config.sections; !sections[len(sections)-1].isAutoexec() {
+				t.Errorf("[autoexec] is not last")
+			}
+		})
+	}
+}
+
+func TestParseConfigKey(t *testing.T) {
+	tests := []struct {
+		ref          string
+		section, key string
+		wantErr      bool
+	}{
+		{"sdl.output", "sdl", "output", false},
+		{" cpu.cycles ", "cpu", "cycles", false},
+		{"sdl", "", "", true},
+		{".output", "", "", true},
+		{"sdl.", "", "", true},
+		{"autoexec.x", "", "", true},
+		{"s]dl.output", "", "", true},
+		{"sdl.out=put", "", "", true},
+	}
+
+	for _, tt := range tests {
+		section, key, err := parseConfigKey(tt.ref)
+		if (err != nil) != tt.wantErr || section != tt.section || key != tt.key {
+			t.Errorf("parseConfigKey(%q) = %q, %q, %v", tt.ref, section, key, err)
+		}
+	}
+}
+
+func TestSetConfigRejectsLineBreaks(t *testing.T) {
+	installer, err := New(Options{Root: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range []string{"texture\n[autoexec]\nformat c:", "texture\r"} {
+		if err := installer.SetConfig("sdl.output", value); err == nil || !strings.Contains(err.Error(), "single line") {
+			t.Errorf("SetConfig(%q) = %v, want a single line error", value, err)
+		}
+	}
+}
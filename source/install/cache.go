@@ -0,0 +1,70 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const downloadCacheDir = "downloads"
+
+// cacheDir returns geoget's directory in the user cache, creating it.
+func cacheDir(elem ...string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve cache directory: %w", err)
+	}
+
+	dir = filepath.Join(append([]string{dir, "geoget"}, elem...)...)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create cache dir: %w", err)
+	}
+
+	return dir, nil
+}
+
+// downloadCached copies a release asset out of the download cache, fetching
+// it from src first if needed. Entries are keyed by the asset ID, which
+// changes when a CI tag is rebuilt, so a moving tag never serves a stale
+// build.
+func downloadCached(ctx context.Context, src ArtifactSource, tag string, asset Asset, destination string, dl downloadOptions) error {
+	dir, err := cacheDir(downloadCacheDir)
+	if err != nil {
+		return err
+	}
+
+	cached := filepath.Join(dir, cacheKey(asset.ID)+"-"+asset.Name)
+
+	if info, err := os.Stat(cached); err != nil || (asset.Size > 0 && info.Size() != asset.Size) {
+		tmp := cached + ".download"
+		defer os.Remove(tmp)
+
+		if err := downloadAsset(ctx, src, tag, asset, tmp, dl); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, cached); err != nil {
+			return fmt.Errorf("store %s in cache: %w", asset.Name, err)
+		}
+	} else if dl.progress != nil {
+		fmt.Fprintf(dl.progress, "%s: using cached download\n", asset.Name)
+	}
+
+	// A hard link spares copying the archive where the cache and the
+	// destination share a file system.
+	if err := os.Link(cached, destination); err == nil {
+		return nil
+	}
+	return copyFile(cached, destination, 0o644)
+}
+
+// cacheKey turns an asset ID into a file name part.
+func cacheKey(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, id)
+}
@@ -0,0 +1,172 @@
+package install
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	compatMatrixURL  = "https://raw.githubusercontent.com/lockesoft66/geoget/main/source/install/templ/compat.json"
+	compatMatrixName = "compat.json"
+	compatRequired   = "required"
+	compatRecommend  = "recommended"
+)
+
+// compatMatrix pairs GEOS builds with the Basebox builds they need.
+// Updated is the date of the last change, as YYYY-MM-DD, so that the newer
+// of the embedded and a refreshed table can be told apart.
+type compatMatrix struct {
+	Updated string       `json:"updated"`
+	Rules   []compatRule `json:"rules"`
+}
+
+type compatRule struct {
+	Geos    string `json:"geos"`
+	Basebox string `json:"basebox"`
+	Level   string `json:"level"`
+	Note    string `json:"note,omitempty"`
+}
+
+// loadCompatMatrix returns the table embedded at build time, or a copy
+// refreshed with --refresh-compat if that is newer. A cached copy that
+// cannot be read is reported to logger and removed.
+func loadCompatMatrix(logger Reporter) (*compatMatrix, error) {
+	data, err := templateFS.ReadFile("templ/" + compatMatrixName)
+	if err != nil {
+		return nil, fmt.Errorf("read compatibility table: %w", err)
+	}
+
+	embedded, err := parseCompatMatrix(data)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := compatCachePath()
+	if err != nil {
+		return embedded, nil
+	}
+
+	data, err = os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return embedded, nil
+	}
+
+	var cached *compatMatrix
+	if err == nil {
+		cached, err = parseCompatMatrix(data)
+	}
+	if err != nil {
+		logger.Printf("Ignoring the refreshed compatibility table in %s: %v\n", path, err)
+		_ = os.Remove(path)
+		return embedded, nil
+	}
+
+	// Dates as YYYY-MM-DD compare as strings.
+	if cached.Updated > embedded.Updated {
+		return cached, nil
+	}
+	return embedded, nil
+}
+
+// refreshCompatMatrix downloads the current table into the user cache.
+func refreshCompatMatrix(ctx context.Context, progress io.Writer) error {
+	path, err := compatCachePath()
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".download"
+	defer os.Remove(tmp)
+
+	if err := downloadFile(ctx, compatMatrixURL, tmp, downloadOptions{progress: progress}); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(tmp)
+	if err != nil {
+		return fmt.Errorf("read compatibility table: %w", err)
+	}
+
+	if _, err := parseCompatMatrix(data); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func parseCompatMatrix(data []byte) (*compatMatrix, error) {
+	var matrix compatMatrix
+	if err := json.Unmarshal(data, &matrix); err != nil {
+		return nil, fmt.Errorf("parse compatibility table: %w", err)
+	}
+
+	for _, rule := range matrix.Rules {
+		if rule.Geos == "" || rule.Basebox == "" {
+			return nil, errors.New("parse compatibility table: rule without geos or basebox tag")
+		}
+		if rule.Level != compatRequired && rule.Level != compatRecommend {
+			return nil, fmt.Errorf("parse compatibility table: unknown level %q", rule.Level)
+		}
+	}
+
+	return &matrix, nil
+}
+
+func compatCachePath() (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, compatMatrixName), nil
+}
+
+func (m *compatMatrix) ruleFor(geosTag string) (compatRule, bool) {
+	for _, rule := range m.Rules {
+		if rule.Geos == geosTag {
+			return rule, true
+		}
+	}
+	return compatRule{}, false
+}
+
+// pairBasebox returns the Basebox tag to install with geosTag. A tag chosen
+// on the command line (pinned) is kept; if it breaks a required pairing the
+// returned warning describes why, and strict turns it into an error.
+func (m *compatMatrix) pairBasebox(geosTag, baseboxTag string, pinned, strict bool) (string, string, error) {
+	rule, ok := m.ruleFor(geosTag)
+	if !ok {
+		return baseboxTag, "", nil
+	}
+
+	if !pinned {
+		return rule.Basebox, "", nil
+	}
+
+	if rule.Basebox == baseboxTag {
+		return baseboxTag, "", nil
+	}
+
+	message := fmt.Sprintf("GEOS %s %s Basebox %s, not %s", geosTag, verbForLevel(rule.Level), rule.Basebox, baseboxTag)
+	if rule.Note != "" {
+		message += " (" + rule.Note + ")"
+	}
+
+	if rule.Level == compatRequired && strict {
+		return "", "", &CompatError{Geos: geosTag, Basebox: baseboxTag, Required: rule.Basebox, Note: rule.Note}
+	}
+
+	return baseboxTag, message, nil
+}
+
+func verbForLevel(level string) string {
+	if level == compatRequired {
+		return "requires"
+	}
+	return "recommends"
+}
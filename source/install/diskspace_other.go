@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package install
+
+// freeSpace reports that the free space is unknown on this platform.
+func freeSpace(path string) (int64, bool) {
+	return 0, false
+}
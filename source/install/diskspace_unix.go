@@ -0,0 +1,15 @@
+//go:build linux || darwin
+
+package install
+
+import "syscall"
+
+// freeSpace returns the bytes available to unprivileged users on the file
+// system of path.
+func freeSpace(path string) (int64, bool) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, false
+	}
+	return int64(uint64(stat.Bavail) * uint64(stat.Bsize)), true
+}
@@ -0,0 +1,128 @@
+package install
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// httpStatusError reports an HTTP response other than 200 OK.
+type httpStatusError struct {
+	url    string
+	code   int
+	status string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %s from %s", e.status, e.url)
+}
+
+// httpOpen GETs url and returns the response body and its length, or -1
+// if unknown.
+func httpOpen(ctx context.Context, url string) (io.ReadCloser, int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GET %s: %w", url, err)
+	}
+
+	resp, err := client(ctx).Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GET %s: %w", url, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, 0, &httpStatusError{url: url, code: resp.StatusCode, status: resp.Status}
+	}
+
+	return resp.Body, resp.ContentLength, nil
+}
+
+// downloadFile fetches url to destination.
+func downloadFile(ctx context.Context, url, destination string, dl downloadOptions) error {
+	return downloadURL(ctx, url, filepathBase(url), "", destination, dl)
+}
+
+// downloadURL fetches url to destination, in segments if enabled and the
+// server supports it. With a checksum, a download that does not match it
+// fails.
+func downloadURL(ctx context.Context, url, name, checksum, destination string, dl downloadOptions) error {
+	if dl.segments > 1 {
+		if done, err := downloadSegmented(ctx, url, name, checksum, destination, dl); done || err != nil {
+			return err
+		}
+	}
+
+	body, size, err := httpOpen(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	return writeDownload(ctx, body, size, name, checksum, destination, dl)
+}
+
+// assetLocator is implemented by sources that serve archives over HTTP,
+// whose downloads can be split into segments.
+type assetLocator interface {
+	assetURL(tag string, asset Asset) string
+}
+
+// downloadAsset fetches an archive of release tag from src to destination,
+// checking it against the asset's checksum if known.
+func downloadAsset(ctx context.Context, src ArtifactSource, tag string, asset Asset, destination string, dl downloadOptions) error {
+	if locator, ok := src.(assetLocator); ok {
+		return downloadURL(ctx, locator.assetURL(tag, asset), asset.Name, asset.SHA256, destination, dl)
+	}
+
+	body, size, err := src.Open(ctx, tag, asset)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	return writeDownload(ctx, body, size, asset.Name, asset.SHA256, destination, dl)
+}
+
+// writeDownload writes r to destination, drawing a progress bar for the
+// download called name if enabled. With a checksum, a download that does
+// not match it fails.
+func writeDownload(ctx context.Context, r io.Reader, size int64, name, checksum, destination string, dl downloadOptions) error {
+	if err := os.MkdirAll(filepathDir(destination), 0o755); err != nil {
+		return fmt.Errorf("create download dir: %w", err)
+	}
+
+	out, err := os.Create(destination)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer out.Close()
+
+	hash := sha256.New()
+	reader := io.TeeReader(dl.limiter.reader(ctx, r), hash)
+
+	var bar *progressWriter
+	if dl.progress != nil {
+		bar = newProgressWriter(name, size, dl.progress)
+		reader = io.TeeReader(reader, bar)
+	}
+
+	if _, err := io.Copy(out, reader); err != nil {
+		return fmt.Errorf("write download: %w", err)
+	}
+
+	if bar != nil {
+		bar.Finish()
+	}
+
+	if sum := hex.EncodeToString(hash.Sum(nil)); checksum != "" && !strings.EqualFold(sum, checksum) {
+		return &ChecksumError{Name: name, Want: checksum, Got: sum}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotInstalled is returned for an install root that holds no
+	// installation.
+	ErrNotInstalled = errors.New("no installation found")
+
+	// ErrRootExists is returned when the install root exists and neither
+	// Force nor Confirm allow replacing it.
+	ErrRootExists = errors.New("install root exists")
+
+	// ErrAborted is returned when Confirm declines to replace the install
+	// root.
+	ErrAborted = errors.New("installation aborted by user")
+
+	// ErrNotLocal is returned by Sync for installs not made from local
+	// build trees.
+	ErrNotLocal = errors.New("not installed from a local build")
+
+	// ErrTokenRequired is returned for installing CI builds of pull
+	// requests or refs without a GitHub token, see HTTPOptions.Token.
+	// Finding the build works without one, but GitHub serves workflow
+	// artifacts to signed-in users only.
+	ErrTokenRequired = errors.New("installing CI builds of pull requests or refs requires a GitHub token (GITHUB_TOKEN or --token)")
+
+	// ErrExtractLimit is returned for archives that exceed ExtractLimits,
+	// such as zip bombs.
+	ErrExtractLimit = errors.New("archive exceeds the extraction limits")
+)
+
+// ReleaseError reports a GEOS or Basebox release that could not be looked
+// up on GitHub.
+type ReleaseError struct {
+	Repo string
+	Tag  string
+	Err  error
+}
+
+func (e *ReleaseError) Error() string {
+	return fmt.Sprintf("fetch release %s of %s: %v", e.Tag, e.Repo, e.Err)
+}
+
+func (e *ReleaseError) Unwrap() error { return e.Err }
+
+// DownloadError reports a build archive that could not be downloaded.
+// Component is "geos <lang>" or "basebox".
+type DownloadError struct {
+	Component string
+	Err       error
+}
+
+func (e *DownloadError) Error() string {
+	return fmt.Sprintf("download %s: %v", e.Component, e.Err)
+}
+
+func (e *DownloadError) Unwrap() error { return e.Err }
+
+// ChecksumError reports a download that does not match the checksum of
+// the release asset, such as an outdated copy on a mirror.
+type ChecksumError struct {
+	Name string
+	Want string
+	Got  string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s: checksum mismatch (expected sha256 %s, got %s)", e.Name, e.Want, e.Got)
+}
+
+// ExtractError reports a build archive that could not be unpacked.
+type ExtractError struct {
+	Component string
+	Err       error
+}
+
+func (e *ExtractError) Error() string {
+	return fmt.Sprintf("extract %s: %v", e.Component, e.Err)
+}
+
+func (e *ExtractError) Unwrap() error { return e.Err }
+
+// CompatError reports a Basebox build that a GEOS build is known not to
+// work with, returned with Options.Strict.
+type CompatError struct {
+	Geos     string
+	Basebox  string
+	Required string
+	Note     string
+}
+
+func (e *CompatError) Error() string {
+	message := fmt.Sprintf("GEOS %s requires Basebox %s, not %s", e.Geos, e.Required, e.Basebox)
+	if e.Note != "" {
+		message += " (" + e.Note + ")"
+	}
+	return message
+}
+
+// DiskSpaceError reports a file system without room for an archive and
+// its unpacked files, found before anything is extracted.
+type DiskSpaceError struct {
+	Path string
+	Need int64
+	Free int64
+}
+
+func (e *DiskSpaceError) Error() string {
+	return fmt.Sprintf("not enough free space in %s: need %s, have %s", e.Path, humanBytes(e.Need), humanBytes(e.Free))
+}
+
+// VerifyError lists what Verify found missing or broken in an install.
+type VerifyError struct {
+	Root     string
+	Problems []string
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("%s is broken: %s", e.Root, strings.Join(e.Problems, "; "))
+}
@@ -0,0 +1,393 @@
+package install
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const geosIniName = "GEOS.INI"
+
+// geosIni is a GEOS.INI file kept line by line, so that comments, ordering,
+// blob values ({ ... }) and line endings survive a round trip unchanged.
+type geosIni struct {
+	preamble   []string
+	categories []*geosIniCategory
+	crlf       bool
+	trailingNL bool
+}
+
+type geosIniCategory struct {
+	name    string
+	header  string
+	entries []geosIniEntry
+}
+
+// geosIniEntry is a key with its raw lines, or a comment/blank line when key
+// is empty. Blob values span several raw lines.
+type geosIniEntry struct {
+	key string
+	raw []string
+}
+
+func parseGeosIni(data []byte) *geosIni {
+	ini := &geosIni{
+		crlf:       bytes.Contains(data, []byte("\r\n")),
+		trailingNL: len(data) == 0 || bytes.HasSuffix(data, []byte("\n")),
+	}
+
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return ini
+	}
+
+	var current *geosIniCategory
+	lines := strings.Split(text, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if name, ok := parseSectionHeader(trimmed); ok {
+			current = &geosIniCategory{name: name, header: line}
+			ini.categories = append(ini.categories, current)
+			continue
+		}
+
+		entry := geosIniEntry{raw: []string{line}}
+
+		if key, value, ok := strings.Cut(trimmed, "="); ok && !strings.HasPrefix(trimmed, ";") {
+			entry.key = strings.TrimSpace(key)
+
+			// A blob runs until its closing brace.
+			value = strings.TrimSpace(value)
+			if strings.HasPrefix(value, "{") && !strings.Contains(value, "}") {
+				for i+1 < len(lines) {
+					i++
+					entry.raw = append(entry.raw, lines[i])
+					if strings.Contains(lines[i], "}") {
+						break
+					}
+				}
+			}
+		}
+
+		if current == nil {
+			ini.preamble = append(ini.preamble, line)
+			continue
+		}
+		current.entries = append(current.entries, entry)
+	}
+
+	return ini
+}
+
+func loadGeosIni(path string) (*geosIni, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseGeosIni(data), nil
+}
+
+func saveGeosIni(path string, ini *geosIni) error {
+	if err := os.WriteFile(path, ini.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// findGeosIni locates GEOS.INI next to LOADER.EXE, whatever its case.
+func findGeosIni(drivecDir string) (string, error) {
+	loaderDir, err := resolveGeosLoaderDir(drivecDir)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(drivecDir, loaderDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(entry.Name(), geosIniName) {
+			return filepath.Join(dir, entry.Name()), nil
+		}
+	}
+
+	return "", fmt.Errorf("%s not found in %s: %w", geosIniName, dir, fs.ErrNotExist)
+}
+
+func (ini *geosIni) category(name string) *geosIniCategory {
+	for _, c := range ini.categories {
+		if strings.EqualFold(c.name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (ini *geosIni) get(category, key string) (string, bool) {
+	c := ini.category(category)
+	if c == nil {
+		return "", false
+	}
+
+	i := c.index(key)
+	if i < 0 {
+		return "", false
+	}
+
+	raw := strings.Join(c.entries[i].raw, "\n")
+	_, value, _ := strings.Cut(raw, "=")
+	return strings.TrimSpace(value), true
+}
+
+func (ini *geosIni) set(category, key, value string) {
+	c := ini.category(category)
+	if c == nil {
+		ini.separate()
+		c = &geosIniCategory{name: category, header: "[" + category + "]"}
+		ini.categories = append(ini.categories, c)
+	}
+
+	entry := geosIniEntry{key: key, raw: []string{key + " = " + value}}
+
+	if i := c.index(key); i >= 0 {
+		c.entries[i] = entry
+		return
+	}
+
+	// Insert after the last key so trailing blank lines keep separating
+	// this category from the next one.
+	pos := len(c.entries)
+	for pos > 0 && c.entries[pos-1].key == "" && strings.TrimSpace(c.entries[pos-1].raw[0]) == "" {
+		pos--
+	}
+
+	c.entries = append(c.entries, geosIniEntry{})
+	copy(c.entries[pos+1:], c.entries[pos:])
+	c.entries[pos] = entry
+}
+
+// unset removes key from category and reports whether it was present.
+func (ini *geosIni) unset(category, key string) bool {
+	c := ini.category(category)
+	if c == nil {
+		return false
+	}
+
+	i := c.index(key)
+	if i < 0 {
+		return false
+	}
+
+	c.entries = append(c.entries[:i], c.entries[i+1:]...)
+	return true
+}
+
+// separate ends the file with a blank line before a new category is added.
+func (ini *geosIni) separate() {
+	blank := geosIniEntry{raw: []string{""}}
+
+	if n := len(ini.categories); n > 0 {
+		c := ini.categories[n-1]
+		if k := len(c.entries); k == 0 || strings.TrimSpace(c.entries[k-1].raw[len(c.entries[k-1].raw)-1]) != "" {
+			c.entries = append(c.entries, blank)
+		}
+		return
+	}
+
+	if n := len(ini.preamble); n > 0 && strings.TrimSpace(ini.preamble[n-1]) != "" {
+		ini.preamble = append(ini.preamble, "")
+	}
+}
+
+func (ini *geosIni) Bytes() []byte {
+	var lines []string
+	lines = append(lines, ini.preamble...)
+
+	for _, c := range ini.categories {
+		lines = append(lines, c.header)
+		for _, entry := range c.entries {
+			lines = append(lines, entry.raw...)
+		}
+	}
+
+	newline := "\n"
+	if ini.crlf {
+		newline = "\r\n"
+	}
+
+	text := strings.Join(lines, newline)
+	if ini.trailingNL {
+		text += newline
+	}
+	return []byte(text)
+}
+
+func (c *geosIniCategory) index(key string) int {
+	for i, entry := range c.entries {
+		if entry.key != "" && strings.EqualFold(entry.key, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// editGeosIni loads the GEOS.INI of drivecDir, lets edit change it and
+// writes it back.
+func editGeosIni(drivecDir string, edit func(ini *geosIni) error) error {
+	path, err := findGeosIni(drivecDir)
+	if err != nil {
+		return err
+	}
+
+	ini, err := loadGeosIni(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", geosIniName, err)
+	}
+
+	if err := edit(ini); err != nil {
+		return err
+	}
+
+	return saveGeosIni(path, ini)
+}
+
+// IniOverride is a GEOS.INI change recorded in the manifest and replayed
+// after every update.
+type IniOverride struct {
+	Category string `json:"category"`
+	Key      string `json:"key"`
+	Value    string `json:"value,omitempty"`
+	Unset    bool   `json:"unset,omitempty"`
+}
+
+// mergeIniOverrides returns base with override replacing any earlier change
+// of the same key.
+func mergeIniOverrides(base []IniOverride, override IniOverride) []IniOverride {
+	var merged []IniOverride
+	for _, o := range base {
+		if !strings.EqualFold(o.Category, override.Category) || !strings.EqualFold(o.Key, override.Key) {
+			merged = append(merged, o)
+		}
+	}
+	return append(merged, override)
+}
+
+func applyIniOverrides(drivecDir string, overrides []IniOverride) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	return editGeosIni(drivecDir, func(ini *geosIni) error {
+		for _, o := range overrides {
+			if o.Unset {
+				ini.unset(o.Category, o.Key)
+			} else {
+				ini.set(o.Category, o.Key, o.Value)
+			}
+		}
+		return nil
+	})
+}
+
+// IniValue is a GEOS.INI value of one installed language.
+type IniValue struct {
+	Lang  string
+	Value string
+}
+
+// Ini reads a GEOS.INI value of every installed language.
+func (i *Installer) Ini(category, key string) ([]IniValue, error) {
+	installs, err := i.installed()
+	if err != nil {
+		return nil, err
+	}
+
+	var values []IniValue
+	for _, li := range installs {
+		path, err := findGeosIni(li.drivecDir)
+		if err != nil {
+			return nil, err
+		}
+
+		ini, err := loadGeosIni(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", geosIniName, err)
+		}
+
+		value, ok := ini.get(category, key)
+		if !ok {
+			return nil, fmt.Errorf("[%s] %s is not set", category, key)
+		}
+
+		values = append(values, IniValue{Lang: li.lang, Value: value})
+	}
+
+	return values, nil
+}
+
+// SetIni sets a GEOS.INI value in every installed language and records it
+// in the manifest, so that updates replay it.
+func (i *Installer) SetIni(category, key, value string) error {
+	return i.updateIni(IniOverride{Category: category, Key: key, Value: value})
+}
+
+// UnsetIni removes a GEOS.INI key like SetIni sets one.
+func (i *Installer) UnsetIni(category, key string) error {
+	return i.updateIni(IniOverride{Category: category, Key: key, Unset: true})
+}
+
+func (i *Installer) updateIni(override IniOverride) error {
+	if err := validateIniOverride(override); err != nil {
+		return err
+	}
+
+	installs, err := i.installed()
+	if err != nil {
+		return err
+	}
+
+	for _, li := range installs {
+		if err := applyIniOverrides(li.drivecDir, []IniOverride{override}); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := loadManifest(i.opts.Root)
+	if err != nil {
+		return err
+	}
+	if manifest == nil {
+		manifest = &Manifest{}
+	}
+
+	manifest.Ini = mergeIniOverrides(manifest.Ini, override)
+	return saveManifest(i.opts.Root, manifest)
+}
+
+func validateIniOverride(o IniOverride) error {
+	category := strings.TrimSpace(o.Category)
+	key := strings.TrimSpace(o.Key)
+
+	if category == "" || strings.ContainsAny(category, "[]\r\n") {
+		return fmt.Errorf("invalid GEOS.INI category: %q", o.Category)
+	}
+
+	if key == "" || strings.ContainsAny(key, "=;\r\n") {
+		return fmt.Errorf("invalid GEOS.INI key: %q", o.Key)
+	}
+
+	if strings.ContainsAny(o.Value, "\r\n") {
+		return fmt.Errorf("GEOS.INI value must be a single line")
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package install
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGeosIniRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"lf", "[system]\nfontid = berkeley\n\n[paths]\nini = GEOS.INI\n"},
+		{"crlf", "[system]\r\nfontid = berkeley\r\n\r\n[paths]\r\nini = GEOS.INI\r\n"},
+		{"no trailing newline", "[system]\r\nfontid = berkeley"},
+		{"preamble and comments", "; GEOS.INI\r\n\r\n[system]\r\n; keep me\r\nfontid=berkeley\r\n"},
+		{"blob", "[ui]\r\ntools = {\r\n  one\r\n  two\r\n}\r\nnext = 1\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(parseGeosIni([]byte(tt.data)).Bytes()); got != tt.data {
+				t.Errorf("round trip changed the file:\ngot  %q\nwant %q", got, tt.data)
+			}
+		})
+	}
+}
+
+func TestGeosIniEdit(t *testing.T) {
+	const data = "[system]\r\nfontid = berkeley\r\n\r\n[ui]\r\ntools = {\r\n  driver = x\r\n}\r\n"
+
+	tests := []struct {
+		name string
+		edit func(ini *geosIni)
+		want string
+	}{
+		{
+			name: "replace keeps CRLF",
+			edit: func(ini *geosIni) { ini.set("SYSTEM", "FontID", "esquire") },
+			want: "[system]\r\nFontID = esquire\r\n\r\n[ui]\r\ntools = {\r\n  driver = x\r\n}\r\n",
+		},
+		{
+			name: "add key before blank line",
+			edit: func(ini *geosIni) { ini.set("system", "memory", "1024") },
+			want: "[system]\r\nfontid = berkeley\r\nmemory = 1024\r\n\r\n[ui]\r\ntools = {\r\n  driver = x\r\n}\r\n",
+		},
+		{
+			name: "add category",
+			edit: func(ini *geosIni) { ini.set("screen 0", "driver", "vga8.geo") },
+			want: "[system]\r\nfontid = berkeley\r\n\r\n[ui]\r\ntools = {\r\n  driver = x\r\n}\r\n\r\n[screen 0]\r\ndriver = vga8.geo\r\n",
+		},
+		{
+			name: "unset",
+			edit: func(ini *geosIni) { ini.unset("system", "fontid") },
+			want: "[system]\r\n\r\n[ui]\r\ntools = {\r\n  driver = x\r\n}\r\n",
+		},
+		{
+			name: "blob lines are not keys",
+			edit: func(ini *geosIni) { ini.set("ui", "driver", "y") },
+			want: "[system]\r\nfontid = berkeley\r\n\r\n[ui]\r\ntools = {\r\n  driver = x\r\n}\r\ndriver = y\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ini := parseGeosIni([]byte(data))
+			tt.edit(ini)
+			if got := string(ini.Bytes()); got != tt.want {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateIniOverride(t *testing.T) {
+	tests := []struct {
+		override IniOverride
+		wantErr  string
+	}{
+		{IniOverride{Category: "system", Key: "fontid", Value: "berkeley"}, ""},
+		{IniOverride{Category: "", Key: "fontid"}, "category"},
+		{IniOverride{Category: "sys]tem", Key: "fontid"}, "category"},
+		{IniOverride{Category: "system", Key: "font=id"}, "key"},
+		{IniOverride{Category: "system", Key: "fontid", Value: "a\r\n[x]"}, "single line"},
+	}
+
+	for _, tt := range tests {
+		err := validateIniOverride(tt.override)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%+v: unexpected error %v", tt.override, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%+v: got error %v, want one about %q", tt.override, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package install
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+const githubAPIBaseURL = "https://api.github.com"
+
+type githubRelease struct {
+	TagName     string        `json:"tag_name"`
+	PublishedAt time.Time     `json:"published_at"`
+	Draft       bool          `json:"draft"`
+	Assets      []githubAsset `json:"assets"`
+}
+
+type githubAsset struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Digest             string `json:"digest"`
+}
+
+func fetchRelease(ctx context.Context, repo, tag string) (*githubRelease, error) {
+	var release githubRelease
+	endpoint := fmt.Sprintf("%s/repos/%s/releases/tags/%s", githubAPIBaseURL, repo, url.PathEscape(tag))
+	if err := getJSON(ctx, endpoint, &release); err != nil {
+		return nil, &ReleaseError{Repo: repo, Tag: tag, Err: err}
+	}
+	return &release, nil
+}
+
+// Release is a published GEOS release.
+type Release struct {
+	Tag       string
+	Published time.Time
+}
+
+// GeosReleases lists the published GEOS releases, oldest first.
+func GeosReleases(ctx context.Context, opts HTTPOptions) ([]Release, error) {
+	httpClient, err := newHTTPClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := listReleases(withClient(ctx, httpClient), GeosRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Release, 0, len(releases))
+	for _, release := range releases {
+		list = append(list, Release{Tag: release.TagName, Published: release.PublishedAt})
+	}
+	return list, nil
+}
+
+// listReleases returns all published releases of repo, oldest first.
+func listReleases(ctx context.Context, repo string) ([]githubRelease, error) {
+	var releases []githubRelease
+
+	for page := 1; ; page++ {
+		var batch []githubRelease
+		endpoint := fmt.Sprintf("%s/repos/%s/releases?per_page=100&page=%d", githubAPIBaseURL, repo, page)
+		if err := getJSON(ctx, endpoint, &batch); err != nil {
+			return nil, fmt.Errorf("list releases of %s: %w", repo, err)
+		}
+
+		for _, release := range batch {
+			if !release.Draft {
+				releases = append(releases, release)
+			}
+		}
+
+		if len(batch) < 100 {
+			break
+		}
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].PublishedAt.Before(releases[j].PublishedAt)
+	})
+
+	return releases, nil
+}
+
+func getJSON(ctx context.Context, endpoint string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client(ctx).Do(req)
+	if err != nil {
+		return fmt.Errorf("GET %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s from %s", resp.Status, endpoint)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode %s: %w", endpoint, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package install
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Version identifies this build of geoget in the User-Agent header. Builds
+// set it with -ldflags "-X geoget/install.Version=...".
+var Version = "dev"
+
+const githubAPIHost = "api.github.com"
+
+// HTTPOptions configure the HTTP client of an Installer, SyncMirror or
+// GeosReleases, used for all downloads and GitHub API requests.
+type HTTPOptions struct {
+	// Proxy is the URL of the proxy for all requests except those to the
+	// hosts in NO_PROXY. If empty, HTTPS_PROXY and HTTP_PROXY are used.
+	Proxy string
+	// CACert is a PEM file of CA certificates trusted in addition to the
+	// system ones, e.g. that of an intercepting proxy.
+	CACert string
+	// Token authenticates requests to api.github.com. If empty, GITHUB_TOKEN
+	// is used.
+	Token string
+	// UserAgent is sent with every request, geoget/<Version> if empty.
+	UserAgent string
+}
+
+// newHTTPClient returns a client set up as opts say.
+func newHTTPClient(opts HTTPOptions) (*http.Client, error) {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", opts.Proxy)
+		}
+		noProxy := os.Getenv("NO_PROXY")
+		if noProxy == "" {
+			noProxy = os.Getenv("no_proxy")
+		}
+		base.Proxy = func(req *http.Request) (*url.URL, error) {
+			if bypassProxy(req.URL.Host, noProxy) {
+				return nil, nil
+			}
+			return proxyURL, nil
+		}
+	}
+
+	if opts.CACert != "" {
+		pem, err := os.ReadFile(opts.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("read CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", opts.CACert)
+		}
+		base.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	transport := &geogetTransport{
+		base:      base,
+		token:     strings.TrimSpace(opts.Token),
+		userAgent: opts.UserAgent,
+	}
+	if transport.token == "" {
+		transport.token = strings.TrimSpace(os.Getenv("GITHUB_TOKEN"))
+	}
+	if transport.userAgent == "" {
+		transport.userAgent = "geoget/" + Version
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+type clientKey struct{}
+
+// withClient returns ctx carrying c, which the requests made with the
+// returned context go through. The exported entry points set it, so that
+// the sources and helpers below them need no client of their own.
+func withClient(ctx context.Context, c *http.Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, c)
+}
+
+// defaultClient serves contexts without a client, set up from the
+// environment alone.
+var defaultClient = sync.OnceValue(func() *http.Client {
+	c, _ := newHTTPClient(HTTPOptions{})
+	return c
+})
+
+func client(ctx context.Context) *http.Client {
+	if c, ok := ctx.Value(clientKey{}).(*http.Client); ok {
+		return c
+	}
+	return defaultClient()
+}
+
+// githubToken returns the token the client of ctx sends to the GitHub API.
+func githubToken(ctx context.Context) string {
+	if t, ok := client(ctx).Transport.(*geogetTransport); ok {
+		return t.token
+	}
+	return ""
+}
+
+// geogetTransport adds the User-Agent to every request and the GitHub
+// token to requests for the GitHub API. It sees each redirect as a new
+// request, so the token never follows a redirect to another host.
+type geogetTransport struct {
+	base      http.RoundTripper
+	token     string
+	userAgent string
+}
+
+func (t *geogetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+
+	req.Header.Del("Authorization")
+	if t.token != "" && req.URL.Scheme == "https" && req.URL.Host == githubAPIHost {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// bypassProxy reports whether host matches the NO_PROXY list noProxy:
+// "*", host names, domain suffixes such as .example.com, and IP addresses
+// or CIDR ranges, each optionally with a port. Loopback addresses are never
+// proxied.
+func bypassProxy(host, noProxy string) bool {
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname = host
+	}
+	hostname = strings.ToLower(hostname)
+
+	if hostname == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(hostname)
+	if ip != nil && ip.IsLoopback() {
+		return true
+	}
+
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		entryHost, entryPort, err := net.SplitHostPort(entry)
+		if err != nil {
+			entryHost, entryPort = entry, ""
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+
+		entryHost = strings.TrimPrefix(entryHost, "*")
+		switch {
+		case strings.HasPrefix(entryHost, "."):
+			if strings.HasSuffix(hostname, entryHost) || hostname == entryHost[1:] {
+				return true
+			}
+		case hostname == entryHost || strings.HasSuffix(hostname, "."+entryHost):
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,38 @@
+package install
+
+import "testing"
+
+func TestBypassProxy(t *testing.T) {
+	tests := []struct {
+		host    string
+		noProxy string
+		want    bool
+	}{
+		{"github.com", "", false},
+		{"localhost:8080", "", true},
+		{"127.0.0.1", "", true},
+		{"[::1]:80", "", true},
+		{"github.com", "*", true},
+		{"github.com", "github.com", true},
+		{"GitHub.com:443", "github.com", true},
+		{"api.github.com", "github.com", true},
+		{"notgithub.com", "github.com", false},
+		{"api.github.com", ".github.com", true},
+		{"github.com", ".github.com", true},
+		{"api.github.com", "*.github.com", true},
+		{"mirror.lan", "example.com, mirror.lan", true},
+		{"10.1.2.3", "10.0.0.0/8", true},
+		{"11.1.2.3:80", "10.0.0.0/8", false},
+		{"mirror.lan", "10.0.0.0/8", false},
+		{"mirror.lan:8080", "mirror.lan:8080", true},
+		{"mirror.lan:80", "mirror.lan:8080", false},
+		{"mirror.lan", "mirror.lan:8080", false},
+		{"github.com", " , ", false},
+	}
+
+	for _, tt := range tests {
+		if got := bypassProxy(tt.host, tt.noProxy); got != tt.want {
+			t.Errorf("bypassProxy(%q, %q) = %v, want %v", tt.host, tt.noProxy, got, tt.want)
+		}
+	}
+}
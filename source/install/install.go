@@ -0,0 +1,755 @@
+// Package install downloads PC/GEOS Ensemble and Basebox builds and sets
+// them up in an install root: drive C:, Basebox configuration, GEOS.INI
+// settings and launchers. The geoget command is a thin CLI over it.
+package install
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultTag is the release installed when no tag is given.
+	DefaultTag = "CI-latest"
+
+	geosArchiveName    = "pcgeos-ensemble_"
+	baseboxArchiveName = "pcgeos-basebox.zip"
+	geosArchiveRoot    = "ensemble"
+	baseboxArchiveRoot = "pcgeos-basebox"
+)
+
+// Options describe an install root and the builds that go into it.
+// Builds left empty select CI-latest, while the languages, sources and
+// Basebox, keyboard, video and mount settings left empty keep what the
+// previous install of the root used.
+type Options struct {
+	// Root is the install root.
+	Root string
+	// Force replaces an existing install root without asking.
+	Force bool
+	// Confirm is asked before an existing install root is replaced. With
+	// neither Force nor Confirm, Install fails with ErrRootExists.
+	Confirm func(root string) (bool, error)
+
+	// GeosTag and BaseboxTag name the releases to install, CI-latest if
+	// empty. An explicit Basebox build is kept even where the
+	// compatibility table pairs the GEOS build with another one.
+	GeosTag    string
+	BaseboxTag string
+	// GeosPR, GeosRef, BaseboxPR and BaseboxRef select the CI build of a
+	// pull request or commit instead of a release.
+	GeosPR     string
+	GeosRef    string
+	BaseboxPR  string
+	BaseboxRef string
+	// GeosDir and BaseboxDir install local build trees, symlinked with
+	// Link.
+	GeosDir    string
+	BaseboxDir string
+	Link       bool
+	// GeosSource and BaseboxSource serve the releases, the GitHub
+	// releases of GeosRepo and BaseboxRepo if nil.
+	GeosSource    ArtifactSource
+	BaseboxSource ArtifactSource
+	// Mirrors lists base URLs or directories tried in order when a release
+	// archive cannot be downloaded from its source, laid out as written by
+	// SyncMirror. Copies that do not match the checksum of the release are
+	// skipped.
+	Mirrors []string
+
+	// Lang lists the Ensemble languages to install, comma-separated. If
+	// empty, a release keeps the languages of the previous install.
+	Lang string
+
+	Basebox  Preset
+	Mounts   []Mount
+	Keyboard string
+	// Video and Resolution set the GEOS screen; ResetSetting drops what
+	// the previous install used.
+	Video      string
+	Resolution string
+
+	// Strict fails on GEOS/Basebox combinations known not to work.
+	Strict bool
+	// RefreshCompat downloads the current compatibility table first.
+	RefreshCompat bool
+	// CacheDownloads keeps release archives in the user cache, so that
+	// installing the same build again needs no download.
+	CacheDownloads bool
+	// LimitRate caps the combined rate of all downloads in bytes per
+	// second; 0 for none.
+	LimitRate int64
+	// Sequential downloads one archive at a time instead of all at once.
+	Sequential bool
+	// Segments splits large archives into that many concurrent ranged
+	// downloads where the server supports it, up to MaxSegments; 0 or 1
+	// for one stream.
+	Segments int
+	// Jobs is the number of files extracted at a time; 0 for one per CPU.
+	Jobs int
+	// Limits guard extraction against archives that unpack to far more
+	// than they should.
+	Limits ExtractLimits
+
+	// HTTP configures the client for downloads and GitHub API requests.
+	HTTP HTTPOptions
+
+	// Reporter receives progress messages; nil discards them.
+	Reporter Reporter
+	// Progress receives download progress bars; nil disables them.
+	Progress io.Writer
+}
+
+// Reporter receives the installer's progress messages. A *log.Logger
+// satisfies it.
+type Reporter interface {
+	Printf(format string, v ...any)
+}
+
+type discardReporter struct{}
+
+func (discardReporter) Printf(string, ...any) {}
+
+// Installer installs, updates and maintains one install root.
+type Installer struct {
+	opts   Options
+	logger Reporter
+	client *http.Client
+
+	geosMirrors    []ArtifactSource
+	baseboxMirrors []ArtifactSource
+	health         *sourceHealth
+}
+
+// New validates opts and returns an Installer for opts.Root.
+func New(opts Options) (*Installer, error) {
+	if strings.TrimSpace(opts.Root) == "" {
+		return nil, errors.New("no install root given")
+	}
+
+	root, err := filepath.Abs(opts.Root)
+	if err != nil {
+		return nil, fmt.Errorf("resolve install root: %w", err)
+	}
+	opts.Root = root
+
+	// Settings such as --scaler depend on the display, which may come
+	// from the defaults of this machine's Basebox build.
+	if err := hostPreset().overlay(opts.Basebox).validate(); err != nil {
+		return nil, err
+	}
+
+	if opts.Keyboard != "" {
+		if _, err := resolveKeyboard(opts.Keyboard, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	opts.Resolution = strings.ToLower(strings.TrimSpace(opts.Resolution))
+	if opts.Resolution != "" && opts.Resolution != ResetSetting {
+		if _, _, err := parseResolution(opts.Resolution); err != nil {
+			return nil, fmt.Errorf("resolution: %w", err)
+		}
+	}
+	opts.Video = normalizeVideoDriver(opts.Video)
+
+	if err := checkSegments(opts.Segments); err != nil {
+		return nil, err
+	}
+
+	if countSet(opts.GeosTag, opts.GeosPR, opts.GeosRef, opts.GeosDir) > 1 {
+		return nil, errors.New("use only one GEOS tag, pull request, ref or local dir")
+	}
+	if countSet(opts.BaseboxTag, opts.BaseboxPR, opts.BaseboxRef, opts.BaseboxDir) > 1 {
+		return nil, errors.New("use only one Basebox tag, pull request, ref or local dir")
+	}
+
+	if opts.GeosDir, err = resolveLocalDir(opts.GeosDir, "GEOS"); err != nil {
+		return nil, err
+	}
+	if opts.BaseboxDir, err = resolveLocalDir(opts.BaseboxDir, "Basebox"); err != nil {
+		return nil, err
+	}
+
+	httpClient, err := newHTTPClient(opts.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	i := &Installer{opts: opts, logger: opts.Reporter, client: httpClient, health: newSourceHealth()}
+	if i.logger == nil {
+		i.logger = discardReporter{}
+	}
+
+	for _, base := range opts.Mirrors {
+		geosMirror, err := mirrorFor(base, GeosRepo)
+		if err != nil {
+			return nil, err
+		}
+		baseboxMirror, err := mirrorFor(base, BaseboxRepo)
+		if err != nil {
+			return nil, err
+		}
+		i.geosMirrors = append(i.geosMirrors, geosMirror)
+		i.baseboxMirrors = append(i.baseboxMirrors, baseboxMirror)
+	}
+
+	return i, nil
+}
+
+// Root returns the absolute install root.
+func (i *Installer) Root() string {
+	return i.opts.Root
+}
+
+// Install downloads the selected builds and sets up the install root,
+// replacing what was there but keeping its settings.
+func (i *Installer) Install(ctx context.Context) error {
+	return i.install(ctx, i.opts)
+}
+
+// Update reinstalls an existing install root. Unlike Install, it also
+// takes the builds and local trees not given in the options from its
+// manifest.
+func (i *Installer) Update(ctx context.Context) error {
+	manifest, err := i.Manifest()
+	if err != nil {
+		return err
+	}
+
+	opts := i.opts
+	opts.Force = true
+
+	if countSet(opts.GeosTag, opts.GeosPR, opts.GeosRef, opts.GeosDir) == 0 {
+		if manifest.GeosDir != "" {
+			opts.GeosDir = manifest.GeosDir
+		} else {
+			opts.GeosTag, opts.GeosPR, opts.GeosRef = buildFromLabel(manifest.GeosTag)
+		}
+	}
+	if countSet(opts.BaseboxTag, opts.BaseboxPR, opts.BaseboxRef, opts.BaseboxDir) == 0 {
+		if manifest.BaseboxDir != "" {
+			opts.BaseboxDir = manifest.BaseboxDir
+		} else if manifest.BaseboxTag != DefaultTag {
+			opts.BaseboxTag, opts.BaseboxPR, opts.BaseboxRef = buildFromLabel(manifest.BaseboxTag)
+		}
+	}
+	if opts.GeosDir != "" || opts.BaseboxDir != "" {
+		opts.Link = opts.Link || manifest.Link
+	}
+
+	return i.install(ctx, opts)
+}
+
+// Manifest returns the manifest of the install root, or ErrNotInstalled.
+func (i *Installer) Manifest() (*Manifest, error) {
+	manifest, err := loadManifest(i.opts.Root)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("%s: %w", i.opts.Root, ErrNotInstalled)
+	}
+	return manifest, nil
+}
+
+// Languages returns the name of the selected GEOS build and the language
+// packages it offers.
+func (i *Installer) Languages(ctx context.Context) (string, []string, error) {
+	if i.opts.GeosDir != "" {
+		return "", nil, errors.New("listing languages needs a release, not a local GEOS build")
+	}
+	ctx = withClient(ctx, i.client)
+
+	t, err := i.resolveTarget(ctx, i.opts)
+	if err != nil {
+		return "", nil, err
+	}
+	if t.releaseErr != nil {
+		return "", nil, t.releaseErr
+	}
+
+	return t.geosTag, t.available, nil
+}
+
+// target is what the options of an install resolve to.
+type target struct {
+	geosTag      string
+	baseboxTag   string
+	geosBuild    *artifactBuild
+	baseboxBuild *artifactBuild
+
+	// geosSources and baseboxSources are the source of each release
+	// followed by the mirrors.
+	geosSources    []ArtifactSource
+	baseboxSources []ArtifactSource
+	// geosListing lists the archives of the GEOS release.
+	geosListing *releaseListing
+
+	// available lists the languages of the GEOS build; nil with
+	// releaseErr set if the release could not be looked up.
+	available  []string
+	releaseErr error
+}
+
+func (i *Installer) resolveTarget(ctx context.Context, opts Options) (*target, error) {
+	var err error
+	logger := i.logger
+
+	t := &target{
+		geosTag:        opts.GeosTag,
+		baseboxTag:     opts.BaseboxTag,
+		geosSources:    append([]ArtifactSource{sourceOrDefault(opts.GeosSource, GeosRepo)}, i.geosMirrors...),
+		baseboxSources: append([]ArtifactSource{sourceOrDefault(opts.BaseboxSource, BaseboxRepo)}, i.baseboxMirrors...),
+	}
+	if t.geosTag == "" {
+		t.geosTag = DefaultTag
+	}
+	if t.baseboxTag == "" {
+		t.baseboxTag = DefaultTag
+	}
+	baseboxPinned := countSet(opts.BaseboxTag, opts.BaseboxPR, opts.BaseboxRef, opts.BaseboxDir) > 0
+
+	if opts.GeosPR != "" || opts.GeosRef != "" {
+		if t.geosBuild, err = resolveArtifactBuild(ctx, GeosRepo, opts.GeosPR, opts.GeosRef, geosArchiveName); err != nil {
+			return nil, err
+		}
+		t.geosTag = t.geosBuild.label
+		logger.Printf("Using GEOS workflow run %d (%s)\n", t.geosBuild.runID, t.geosBuild.label)
+	}
+	if opts.BaseboxPR != "" || opts.BaseboxRef != "" {
+		if t.baseboxBuild, err = resolveArtifactBuild(ctx, BaseboxRepo, opts.BaseboxPR, opts.BaseboxRef, baseboxArchiveName); err != nil {
+			return nil, err
+		}
+		t.baseboxTag = t.baseboxBuild.label
+		logger.Printf("Using Basebox workflow run %d (%s)\n", t.baseboxBuild.runID, t.baseboxBuild.label)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if opts.RefreshCompat {
+		if err := refreshCompatMatrix(ctx, opts.Progress); err != nil {
+			logger.Printf("Could not refresh the compatibility table: %v\n", err)
+		}
+	}
+
+	compat, err := loadCompatMatrix(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	pairedTag, warning, err := compat.pairBasebox(t.geosTag, t.baseboxTag, baseboxPinned, opts.Strict)
+	if err != nil {
+		return nil, err
+	}
+	if warning != "" {
+		logger.Printf("Warning: %s\n", warning)
+	} else if pairedTag != t.baseboxTag {
+		logger.Printf("GEOS %s needs Basebox %s, selecting it\n", t.geosTag, pairedTag)
+	}
+	t.baseboxTag = pairedTag
+
+	if opts.GeosDir != "" {
+		t.geosTag = "local"
+	}
+	if opts.BaseboxDir != "" {
+		t.baseboxTag = "local"
+	}
+
+	switch {
+	case opts.GeosDir != "":
+	case t.geosBuild != nil:
+		t.available = assetLanguages(t.geosBuild.assetNames())
+	default:
+		if t.geosListing, t.releaseErr = i.listRelease(ctx, GeosRepo, t.geosSources, t.geosTag); t.releaseErr == nil {
+			t.available = assetLanguages(assetNames(t.geosListing.assets))
+		}
+	}
+
+	return t, nil
+}
+
+func (i *Installer) install(ctx context.Context, opts Options) error {
+	ctx = withClient(ctx, i.client)
+	logger := i.logger
+	installRoot := opts.Root
+
+	if recovered, err := recoverInstallRoot(installRoot); err != nil {
+		return err
+	} else if recovered {
+		logger.Printf("Restored the install left behind by an interrupted update\n")
+	}
+
+	previous, err := loadManifest(installRoot)
+	if err != nil {
+		return err
+	}
+	if previous != nil {
+		if opts.GeosSource == nil && previous.GeosSource != "" {
+			if opts.GeosSource, err = ParseSource(previous.GeosSource, GeosRepo); err != nil {
+				return err
+			}
+		}
+		if opts.BaseboxSource == nil && previous.BaseboxSource != "" {
+			if opts.BaseboxSource, err = ParseSource(previous.BaseboxSource, BaseboxRepo); err != nil {
+				return err
+			}
+		}
+		// A local GEOS build installs a single language, so it does not
+		// inherit several.
+		if opts.Lang == "" && opts.GeosDir == "" {
+			opts.Lang = strings.Join(previous.languages(), ",")
+		}
+	}
+
+	t, err := i.resolveTarget(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if t.releaseErr != nil {
+		logger.Printf("Could not list the release languages, assuming a known language: %v\n", t.releaseErr)
+	}
+
+	// Anyone may look up workflow artifacts, but GitHub serves them only
+	// to signed-in users.
+	if (t.geosBuild != nil || t.baseboxBuild != nil) && githubToken(ctx) == "" {
+		return ErrTokenRequired
+	}
+
+	languages, err := resolveLanguages(opts.Lang, t.available)
+	if err != nil {
+		return err
+	}
+	if opts.GeosDir != "" && len(languages) > 1 {
+		return errors.New("a local GEOS build installs a single language")
+	}
+
+	geosTag, baseboxTag := t.geosTag, t.baseboxTag
+	geosBuild, baseboxBuild := t.geosBuild, t.baseboxBuild
+
+	baseboxDir := filepath.Join(installRoot, "basebox")
+	installs := languageInstalls(installRoot, languages)
+
+	manifest := newManifest(opts, geosTag, baseboxTag, languages, previous)
+	if err := checkVideo(manifest.Video, manifest.Resolution); err != nil {
+		return err
+	}
+	if err := hostPreset().overlay(manifest.Basebox).validate(); err != nil {
+		return err
+	}
+
+	if err := confirmInstallRoot(installRoot, opts.Force, opts.Confirm); err != nil {
+		return err
+	}
+
+	// The Basebox release is only listed where its asset IDs or checksums
+	// are needed.
+	var baseboxListing *releaseListing
+	if (opts.CacheDownloads || len(i.baseboxMirrors) > 0) && opts.BaseboxDir == "" && baseboxBuild == nil {
+		if baseboxListing, err = i.listRelease(ctx, BaseboxRepo, t.baseboxSources, baseboxTag); err != nil {
+			logger.Printf("Could not look up the Basebox release, downloading without cache or checksum: %v\n", err)
+		}
+	}
+
+	tempDir, err := os.MkdirTemp("", "geoget-*")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Archives are unpacked into a staging tree next to the install root,
+	// which then takes its place, so no file is copied twice.
+	staging, err := prepareStaging(installRoot)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	/*
+		Download and extract
+	*/
+
+	logger.Printf("Installing in %s\n", installRoot)
+
+	baseboxZip := filepath.Join(tempDir, "pcgeos-basebox.zip")
+	dl := downloadOptions{progress: opts.Progress, limiter: newRateLimiter(opts.LimitRate), segments: opts.Segments}
+	eo := extractOptions{jobs: opts.Jobs, limits: opts.Limits, logger: logger, space: &spaceReservation{}}
+
+	var jobs []downloadJob
+	for _, li := range languageInstalls(staging, languages) {
+		if opts.GeosDir != "" {
+			break
+		}
+		lang, drivecDir := li.lang, li.drivecDir
+		zipPath := geosZipPath(tempDir, lang)
+		jobs = append(jobs, downloadJob{
+			component: "geos " + lang,
+			fetch: func(ctx context.Context) error {
+				logger.Printf("Downloading PC/GEOS Ensemble build: %s %s\n", geosTag, lang)
+				if geosBuild != nil {
+					return geosBuild.downloadArtifact(ctx, geosArchiveName+lang+".zip", zipPath, dl, eo.limits)
+				}
+				return i.fetchReleaseAsset(ctx, GeosRepo, t.geosSources, geosTag, t.geosListing, geosArchiveName+lang+".zip", zipPath, opts.CacheDownloads, dl)
+			},
+			extract: func(ctx context.Context) error {
+				logger.Printf("Extracting Ensemble archive: %s\n", lang)
+				return extractArchive(ctx, zipPath, drivecDir, "", eo)
+			},
+		})
+	}
+
+	if opts.BaseboxDir == "" {
+		jobs = append(jobs, downloadJob{
+			component: "basebox",
+			fetch: func(ctx context.Context) error {
+				logger.Printf("Downloading Basebox: %s\n", baseboxTag)
+				if baseboxBuild != nil {
+					return baseboxBuild.downloadArtifact(ctx, baseboxArchiveName, baseboxZip, dl, eo.limits)
+				}
+				return i.fetchReleaseAsset(ctx, BaseboxRepo, t.baseboxSources, baseboxTag, baseboxListing, baseboxArchiveName, baseboxZip, opts.CacheDownloads, dl)
+			},
+			extract: func(ctx context.Context) error {
+				logger.Printf("Extracting Basebox archive\n")
+				return extractArchive(ctx, baseboxZip, filepath.Join(staging, "basebox"), baseboxArchiveRoot, eo)
+			},
+		})
+	}
+
+	if err := runDownloads(ctx, jobs, opts.Sequential); err != nil {
+		return err
+	}
+
+	/*
+		Prepare
+	*/
+
+	userFiles, err := saveUserFiles(installRoot)
+	if err != nil {
+		return err
+	}
+
+	backup, err := swapInstallRoot(installRoot, staging)
+	if err != nil {
+		return err
+	}
+
+	// From here on a failure, including Ctrl-C, puts the previous install
+	// back or removes the partial one.
+	done := false
+	defer func() {
+		if done {
+			return
+		}
+		if err := rollbackInstallRoot(installRoot, backup); err != nil {
+			logger.Printf("Could not roll back: %v\n", err)
+		} else if backup != "" {
+			logger.Printf("Restored the previous install\n")
+		}
+	}()
+
+	for _, li := range installs {
+		if err := prepareInstallDirs(installRoot, li.drivecDir, baseboxDir); err != nil {
+			return err
+		}
+	}
+
+	if err := restoreUserFiles(installRoot, userFiles); err != nil {
+		return err
+	}
+
+	if opts.GeosDir != "" || opts.BaseboxDir != "" {
+		logger.Printf("Installing local build\n")
+		if _, err := syncInstall(ctx, installRoot, manifest); err != nil {
+			return err
+		}
+	}
+
+	baseboxBinary, err := detectBaseboxBinary(baseboxDir)
+	if err != nil {
+		return err
+	}
+	logger.Printf("Using Basebox executable: %s (%s)\n", baseboxBinary.relPath, baseboxBinary.arch)
+
+	/*
+		Write config, create Launchers
+	*/
+	for _, li := range installs {
+		if err := i.configureLanguage(installRoot, baseboxBinary, manifest, li); err != nil {
+			return err
+		}
+	}
+
+	if err := saveManifest(installRoot, manifest); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done = true
+	if err := finishInstallRoot(installRoot, backup); err != nil {
+		logger.Printf("Could not finish the update: %v\n", err)
+	}
+
+	logger.Printf("Deployment complete.\n")
+	return nil
+}
+
+// downloadJob fetches one archive of an install and unpacks it.
+type downloadJob struct {
+	component string
+	fetch     func(ctx context.Context) error
+	extract   func(ctx context.Context) error
+}
+
+// run fetches the archive and extracts it right away, returning a
+// *DownloadError or *ExtractError.
+func (j downloadJob) run(ctx context.Context) error {
+	if err := j.fetch(ctx); err != nil {
+		return &DownloadError{Component: j.component, Err: err}
+	}
+	if err := j.extract(ctx); err != nil {
+		return &ExtractError{Component: j.component, Err: err}
+	}
+	return nil
+}
+
+// runDownloads runs jobs in parallel, so that each archive is extracted as
+// soon as it is downloaded, or one after the other if sequential is set.
+// The first failure cancels the other jobs and is returned as a
+// *DownloadError or *ExtractError; cancelling ctx returns its error.
+func runDownloads(ctx context.Context, jobs []downloadJob, sequential bool) error {
+	if sequential {
+		for _, job := range jobs {
+			if err := job.run(ctx); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return err
+			}
+		}
+		return nil
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(jobs))
+
+	for n, job := range jobs {
+		wg.Add(1)
+		go func(n int, job downloadJob) {
+			defer wg.Done()
+			if errs[n] = job.run(jobCtx); errs[n] != nil {
+				cancel()
+			}
+		}(n, job)
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// configureLanguage writes the Basebox config, GEOS.INI settings and
+// launcher of one installed language.
+func (i *Installer) configureLanguage(installRoot string, binary baseboxBinary, manifest *Manifest, li languageInstall) error {
+	baseboxDir := filepath.Join(installRoot, "basebox")
+
+	settings, err := settingsFor(binary, manifest, li.lang)
+	if err != nil {
+		return err
+	}
+
+	if err := writeBaseboxConfig(baseboxDir, li.drivecDir, li.configName, settings); err != nil {
+		return err
+	}
+
+	// Each language build comes set up for its own keyboard, so GEOS.INI
+	// only changes for an explicit layout.
+	if manifest.Keyboard != "" {
+		if applied, err := writeGeosKeyboard(li.drivecDir, settings.keyboard); err != nil {
+			return err
+		} else if !applied {
+			i.logger.Printf("GEOS keyboard driver %s not found, keeping the GEOS.INI default\n", settings.keyboard.geosDriver)
+		}
+	}
+
+	if err := writeGeosVideo(li.drivecDir, manifest.Video, manifest.Resolution); err != nil {
+		return err
+	}
+
+	if len(manifest.Ini) > 0 {
+		i.logger.Printf("Applying %d GEOS.INI override(s)\n", len(manifest.Ini))
+		if err := applyIniOverrides(li.drivecDir, manifest.Ini); err != nil {
+			return err
+		}
+	}
+
+	return createLaunchers(installRoot, binary.arch, li.launcher, li.configName)
+}
+
+func geosZipPath(tempDir, lang string) string {
+	return filepath.Join(tempDir, fmt.Sprintf("pcgeos-ensemble_%s.zip", lang))
+}
+
+// extractArchive unpacks a downloaded archive into its place in the
+// staging tree and removes it, freeing the space for the other archives.
+func extractArchive(ctx context.Context, archivePath, destination, root string, eo extractOptions) error {
+	if err := extractZip(ctx, archivePath, destination, root, eo); err != nil {
+		return err
+	}
+	return os.Remove(archivePath)
+}
+
+// buildFromLabel turns a tag recorded in the manifest back into a release
+// tag, or the pull request or ref of a CI build labelled "pr-12@abc1234".
+func buildFromLabel(label string) (string, string, string) {
+	build := label
+	if i := strings.LastIndex(label, "@"); i >= 0 {
+		build = label[:i]
+	}
+
+	if pr, ok := strings.CutPrefix(build, "pr-"); ok && build != label {
+		return "", pr, ""
+	}
+	if ref, ok := strings.CutPrefix(build, "ref-"); ok && build != label {
+		if unescaped, err := url.PathUnescape(ref); err == nil {
+			ref = unescaped
+		}
+		return "", "", ref
+	}
+	return label, "", ""
+}
+
+func countSet(values ...string) int {
+	n := 0
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,127 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// keyboardLayout ties a DOS keyboard layout to its code page and the GEOS
+// keyboard driver built for it, which not every build ships.
+type keyboardLayout struct {
+	name       string
+	codepage   string
+	geosDriver string
+}
+
+var keyboardLayouts = map[string]keyboardLayout{
+	"us": {name: "us", codepage: "437", geosDriver: "kbd.geo"},
+	"uk": {name: "uk", codepage: "850", geosDriver: "ukkbd.geo"},
+	"gr": {name: "gr", codepage: "850", geosDriver: "grkbd.geo"},
+	"sg": {name: "sg", codepage: "850", geosDriver: "sgkbd.geo"},
+	"fr": {name: "fr", codepage: "850", geosDriver: "frkbd.geo"},
+	"it": {name: "it", codepage: "850", geosDriver: "itkbd.geo"},
+	"sp": {name: "sp", codepage: "850", geosDriver: "spkbd.geo"},
+	"nl": {name: "nl", codepage: "850", geosDriver: "nlkbd.geo"},
+	"sv": {name: "sv", codepage: "850", geosDriver: "svkbd.geo"},
+	"dk": {name: "dk", codepage: "850", geosDriver: "dkkbd.geo"},
+}
+
+// languageKeyboards maps Ensemble language packages to their keyboard.
+var languageKeyboards = map[string]string{
+	"nc":     "us",
+	"german": "gr",
+}
+
+// resolveKeyboard returns the layout for an explicit --keyboard value, or the
+// one matching the GEOS language. The value may carry a code page, as in
+// "gr:437".
+func resolveKeyboard(override, lang string) (keyboardLayout, error) {
+	value := strings.ToLower(strings.TrimSpace(override))
+	if value == "" {
+		value = languageKeyboards[lang]
+		if value == "" {
+			value = "us"
+		}
+	}
+
+	name, codepage, _ := strings.Cut(value, ":")
+	layout, ok := keyboardLayouts[name]
+	if !ok {
+		return keyboardLayout{}, fmt.Errorf("unknown keyboard layout %q (known: %s)", name, strings.Join(knownKeyboards(), ", "))
+	}
+
+	if codepage != "" {
+		if !isNumeric(codepage) {
+			return keyboardLayout{}, fmt.Errorf("code page must be numeric: %q", codepage)
+		}
+		layout.codepage = codepage
+	}
+
+	return layout, nil
+}
+
+func knownKeyboards() []string {
+	var names []string
+	for name := range keyboardLayouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyKeyboard loads the DOS layout in the autoexec. The US layout with its
+// default code page is what Basebox starts with, so it needs no command.
+func applyKeyboard(config *baseboxConfig, layout keyboardLayout) {
+	if layout.name == "" || layout.name == "us" && layout.codepage == "437" {
+		return
+	}
+	insertAutoexec(config, fmt.Sprintf("keyb %s %s", layout.name, layout.codepage))
+}
+
+// writeGeosKeyboard points GEOS.INI at the keyboard driver for layout. It
+// reports false and leaves GEOS.INI alone if the build lacks that driver.
+func writeGeosKeyboard(drivecDir string, layout keyboardLayout) (bool, error) {
+	loaderDir, err := resolveGeosLoaderDir(drivecDir)
+	if err != nil {
+		return false, err
+	}
+
+	found, err := findFileFold(filepath.Join(drivecDir, loaderDir), layout.geosDriver)
+	if err != nil || found == "" {
+		return false, err
+	}
+
+	err = editGeosIni(drivecDir, func(ini *geosIni) error {
+		ini.set("keyboard", "driver", filepath.Base(found))
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// findFileFold searches root for a file named name, ignoring case.
+func findFileFold(root, name string) (string, error) {
+	var found string
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.EqualFold(d.Name(), name) {
+			found = path
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("search %s: %w", name, err)
+	}
+
+	return found, nil
+}
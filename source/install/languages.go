@@ -0,0 +1,108 @@
+package install
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// languageAliases maps the names users type to Ensemble language packages.
+var languageAliases = map[string]string{
+	"":        "nc",
+	"nc":      "nc",
+	"en":      "nc",
+	"us":      "nc",
+	"english": "nc",
+	"de":      "german",
+	"gr":      "german",
+	"german":  "german",
+	"deutsch": "german",
+}
+
+// assetLanguages lists the language packages of a GEOS release, taken
+// from its pcgeos-ensemble_<lang>.zip assets.
+func assetLanguages(assets []string) []string {
+	var languages []string
+	for _, asset := range assets {
+		name, ok := strings.CutPrefix(asset, geosArchiveName)
+		if !ok {
+			continue
+		}
+		if lang, ok := strings.CutSuffix(name, ".zip"); ok && lang != "" {
+			languages = append(languages, lang)
+		}
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// resolveLanguage maps input to a language package. With the release's
+// languages known, the package must be among them; without (e.g. API rate
+// limit) only the known aliases are accepted.
+func resolveLanguage(input string, available []string) (string, error) {
+	value := strings.ToLower(strings.TrimSpace(input))
+
+	lang, ok := languageAliases[value]
+	if !ok {
+		lang = value
+	}
+
+	if available == nil {
+		if !ok {
+			return "", fmt.Errorf("unknown GEOS language %q", input)
+		}
+		return lang, nil
+	}
+
+	if !containsString(available, lang) {
+		return "", fmt.Errorf("GEOS language %q is not available (available: %s)", input, strings.Join(available, ", "))
+	}
+
+	return lang, nil
+}
+
+// resolveLanguages resolves a comma-separated --lang value such as "nc,gr".
+func resolveLanguages(input string, available []string) ([]string, error) {
+	if strings.TrimSpace(input) == "" {
+		lang, err := resolveLanguage(input, available)
+		if err != nil {
+			return nil, err
+		}
+		return []string{lang}, nil
+	}
+
+	var languages []string
+
+	// Empty items, as in "gr,", are left out rather than read as the
+	// default language.
+	for _, part := range strings.Split(input, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		lang, err := resolveLanguage(part, available)
+		if err != nil {
+			return nil, err
+		}
+		if !containsString(languages, lang) {
+			languages = append(languages, lang)
+		}
+	}
+
+	if len(languages) == 0 {
+		return nil, fmt.Errorf("no GEOS language in %q", input)
+	}
+
+	return languages, nil
+}
+
+// LanguageAliases lists the aliases accepted for lang, for --lang list.
+func LanguageAliases(lang string) []string {
+	var aliases []string
+	for alias, target := range languageAliases {
+		if target == lang && alias != "" && alias != lang {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
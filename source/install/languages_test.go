@@ -0,0 +1,45 @@
+package install
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveLanguages(t *testing.T) {
+	release := []string{"german", "nc", "ru"}
+
+	tests := []struct {
+		input     string
+		available []string
+		want      []string
+		wantErr   bool
+	}{
+		{"", nil, []string{"nc"}, false},
+		{"  ", release, []string{"nc"}, false},
+		{"gr", nil, []string{"german"}, false},
+		{"EN, Deutsch", nil, []string{"nc", "german"}, false},
+		{"nc,gr,en,de", nil, []string{"nc", "german"}, false},
+		{"gr,", nil, []string{"german"}, false},
+		{",gr, ,nc", nil, []string{"german", "nc"}, false},
+		{",", nil, nil, true},
+		{" , ", release, nil, true},
+		{"ru", release, []string{"ru"}, false},
+		{"ru", nil, nil, true},
+		{"gr,fr", release, nil, true},
+		{"gr", []string{"nc"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveLanguages(tt.input, tt.available)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("resolveLanguages(%q, %q) = %q, %v; want %q", tt.input, tt.available, got, err, tt.want)
+		}
+	}
+}
+
+func TestAssetLanguages(t *testing.T) {
+	assets := []string{"pcgeos-ensemble_nc.zip", "pcgeos-basebox.zip", "pcgeos-ensemble_german.zip", "pcgeos-ensemble_.zip", "pcgeos-ensemble_nc.txt"}
+	if got, want := assetLanguages(assets), []string{"german", "nc"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("assetLanguages = %q, want %q", got, want)
+	}
+}
@@ -1,8 +1,10 @@
-package main
+package install
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -11,11 +13,12 @@ import (
 type baseboxBinary struct {
 	arch    string
 	relPath string
+	preset  Preset
 }
 
 type launcherTemplate struct {
 	templateName string
-	outputName   string
+	extension    string
 	executable   bool
 }
 
@@ -29,20 +32,22 @@ var (
 	}
 )
 
-func createLaunchers(installRoot, arch string) error {
+func createLaunchers(installRoot, arch, name, configName string) error {
 	launchers, err := launcherTemplatesForArch(arch)
 	if err != nil {
 		return err
 	}
 
 	for _, launcher := range launchers {
-		dest := filepath.Join(installRoot, launcher.outputName)
+		dest := filepath.Join(installRoot, name+launcher.extension)
 
 		content, err := templateFS.ReadFile("templ/" + launcher.templateName)
 		if err != nil {
 			return fmt.Errorf("read launcher template %s: %w", launcher.templateName, err)
 		}
 
+		content = []byte(strings.ReplaceAll(string(content), "{{CONFIG_FILE}}", configName))
+
 		if err := os.WriteFile(dest, content, 0o755); err != nil {
 			return fmt.Errorf("write launcher %s: %w", dest, err)
 		}
@@ -57,92 +62,77 @@ func createLaunchers(installRoot, arch string) error {
 	return nil
 }
 
+// Launch starts the launcher of an installed language, the first one if
+// lang is empty, and waits for Basebox to exit.
+func (i *Installer) Launch(ctx context.Context, lang string) error {
+	installs, err := i.installed()
+	if err != nil {
+		return err
+	}
+
+	li := installs[0]
+	if lang != "" {
+		name, ok := languageAliases[strings.ToLower(lang)]
+		if !ok {
+			name = strings.ToLower(lang)
+		}
+
+		found := false
+		for _, candidate := range installs {
+			if candidate.lang == name {
+				li, found = candidate, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("language %s is not installed in %s", lang, i.opts.Root)
+		}
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/c", filepath.Join(i.opts.Root, li.launcher+".cmd"))
+	} else {
+		cmd = exec.CommandContext(ctx, filepath.Join(i.opts.Root, li.launcher+".sh"))
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("run launcher: %w", err)
+	}
+
+	return nil
+}
+
 func launcherTemplatesForArch(arch string) ([]launcherTemplate, error) {
 	switch arch {
 	case "l64", "mac", "rpi64":
 		return []launcherTemplate{
 			{
 				templateName: fmt.Sprintf("ensemble.%s.sh", arch),
-				outputName:   "ensemble.sh",
+				extension:    ".sh",
 				executable:   true,
 			},
 		}, nil
 	case "nt":
 		return []launcherTemplate{
-			{templateName: "ensemble.nt.cmd", outputName: "ensemble.cmd"},
+			{templateName: "ensemble.nt.cmd", extension: ".cmd"},
 		}, nil
 	case "nt64":
 		return []launcherTemplate{
-			{templateName: "ensemble.nt64.cmd", outputName: "ensemble.cmd"},
+			{templateName: "ensemble.nt64.cmd", extension: ".cmd"},
 		}, nil
 	default:
 		return nil, fmt.Errorf("unsupported basebox architecture %q", arch)
 	}
 }
 
-func writeBaseboxConfig(baseboxDir, drivecDir string) error {
-
-	var loaderDir string
-	var config string
-
-	data, err := templateFS.ReadFile("templ/basebox.conf")
-	if err != nil {
-		return fmt.Errorf("read basebox template: %w", err)
-	}
-
-	config = string(data);
-
-	loaderDir, err = resolveGeosLoaderDir(drivecDir)
-	if err == nil {
-		config = strings.ReplaceAll(config, "{{LOADER_DIR}}", loaderDir)
-	}
-
-	hostPath := filepath.Clean(drivecDir)
-	config = strings.ReplaceAll(config, "{{HOST_PATH}}", hostPath)
-
-	dest := filepath.Join(baseboxDir, "basebox.conf")
-	if err := os.WriteFile(dest, []byte(config), 0o644); err != nil {
-		return fmt.Errorf("write basebox.conf: %w", err)
-	}
-
-	return nil
-}
-
-func ensureExecutables(baseboxDir string) error {
-	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
-		for _, relPath := range baseboxBinaryPaths {
-			exe := filepath.Join(baseboxDir, relPath)
-			if exists(exe) {
-				if err := os.Chmod(exe, 0o755); err != nil {
-					return fmt.Errorf("mark executable %s: %w", exe, err)
-				}
-			}
-		}
-	}
-
-	// Also mark shell launchers shipped inside the Basebox archive.
-	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
-		_ = filepath.Walk(baseboxDir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil
-			}
-			if info.IsDir() {
-				return nil
-			}
-			if strings.HasSuffix(info.Name(), ".sh") {
-				_ = os.Chmod(path, 0o755)
-			}
-			return nil
-		})
-	}
-
-	return nil
-}
-
 func detectBaseboxBinary(baseboxDir string) (baseboxBinary, error) {
 	for _, arch := range orderedBaseboxArchs() {
 		if relPath, ok := binaryPathForArch(baseboxDir, arch); ok {
-			return baseboxBinary{arch: arch, relPath: relPath}, nil
+			return baseboxBinary{arch: arch, relPath: relPath, preset: defaultPresetForArch(arch)}, nil
 		}
 	}
 
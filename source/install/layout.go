@@ -0,0 +1,66 @@
+package install
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// languageInstall names the parts of an install root that belong to one
+// Ensemble language. A single-language install keeps the classic names;
+// side-by-side languages get their own drive, config and launcher.
+type languageInstall struct {
+	lang       string
+	drivecDir  string
+	configName string
+	launcher   string
+}
+
+func languageInstalls(installRoot string, languages []string) []languageInstall {
+	if len(languages) == 1 {
+		return []languageInstall{{
+			lang:       languages[0],
+			drivecDir:  filepath.Join(installRoot, "drivec"),
+			configName: baseboxConfigName,
+			launcher:   "ensemble",
+		}}
+	}
+
+	installs := make([]languageInstall, 0, len(languages))
+	for _, lang := range languages {
+		installs = append(installs, languageInstall{
+			lang:       lang,
+			drivecDir:  filepath.Join(installRoot, "drivec-"+lang),
+			configName: fmt.Sprintf("basebox-%s.conf", lang),
+			launcher:   "ensemble-" + lang,
+		})
+	}
+	return installs
+}
+
+// installed returns the language installs of the install root, or
+// ErrNotInstalled if it holds no installation.
+func (i *Installer) installed() ([]languageInstall, error) {
+	if !exists(filepath.Join(i.opts.Root, "basebox")) {
+		return nil, fmt.Errorf("%s: %w", i.opts.Root, ErrNotInstalled)
+	}
+	return installedLanguages(i.opts.Root)
+}
+
+// installedLanguages returns the language installs recorded in the
+// manifest of installRoot.
+func installedLanguages(installRoot string) ([]languageInstall, error) {
+	manifest, err := loadManifest(installRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var languages []string
+	if manifest != nil {
+		languages = manifest.languages()
+	}
+	if len(languages) == 0 {
+		languages = []string{"nc"}
+	}
+
+	return languageInstalls(installRoot, languages), nil
+}
@@ -0,0 +1,252 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localGeosTarget returns where a local PC/GEOS build tree goes inside
+// drivecDir. A tree with LOADER.EXE at its top is the Ensemble folder
+// itself and is placed like the release archive's "ensemble" folder.
+func localGeosTarget(src, drivecDir string) (string, error) {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return "", fmt.Errorf("read local build %s: %w", src, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(entry.Name(), "loader.exe") {
+			return filepath.Join(drivecDir, geosArchiveRoot), nil
+		}
+	}
+
+	return drivecDir, nil
+}
+
+// syncLocalTree mirrors the files of a local build tree into dst and
+// returns the install-relative paths it updated. Names are lower-cased to
+// match the release archives. Files that are new, differ in size or are
+// newer than their copy are updated, keeping their modes and modification
+// times; GEOS.INI and other .ini files are only created, never overwritten,
+// since the installer edits them in place. Symbolic links are copied as
+// links if they stay inside src. With link set, files are symlinked instead
+// of copied, except .ini files.
+func syncLocalTree(ctx context.Context, src, dst string, link bool) ([]string, error) {
+	type syncedDir struct {
+		path string
+		info fs.FileInfo
+	}
+	var updated []string
+	var dirs []syncedDir
+
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, strings.ToLower(rel))
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+			dirs = append(dirs, syncedDir{path: target, info: info})
+			return nil
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 && !link {
+			changed, err := syncLocalLink(path, target, src)
+			if changed {
+				updated = append(updated, strings.ToLower(rel))
+			}
+			return err
+		}
+
+		isIni := strings.EqualFold(filepath.Ext(path), ".ini")
+
+		current, err := os.Lstat(target)
+		if err == nil && (isIni || !localFileChanged(info, current, link)) {
+			return nil
+		}
+
+		if link && !isIni {
+			if err := linkFile(path, target); err != nil {
+				return err
+			}
+		} else if err := copyFile(path, target, info.Mode().Perm()); err != nil {
+			return err
+		}
+
+		updated = append(updated, strings.ToLower(rel))
+		return nil
+	})
+
+	if err != nil {
+		return updated, fmt.Errorf("sync %s: %w", src, err)
+	}
+
+	// Copying into a folder changes its time, so folders get their modes
+	// and times last, deepest first.
+	for n := len(dirs) - 1; n >= 0; n-- {
+		dir := dirs[n]
+		if err := os.Chmod(dir.path, dir.info.Mode().Perm()|0o700); err != nil {
+			return updated, fmt.Errorf("sync %s: %w", src, err)
+		}
+		if err := os.Chtimes(dir.path, dir.info.ModTime(), dir.info.ModTime()); err != nil {
+			return updated, fmt.Errorf("sync %s: %w", src, err)
+		}
+	}
+
+	return updated, nil
+}
+
+// syncLocalLink recreates the symbolic link src at dst, lower-casing its
+// target like the files it may point to, and reports whether dst changed.
+// Links that leave root are rejected.
+func syncLocalLink(src, dst, root string) (bool, error) {
+	linkTarget, err := os.Readlink(src)
+	if err != nil {
+		return false, fmt.Errorf("read link %s: %w", src, err)
+	}
+
+	linkTarget, err = safeLinkTarget(src, linkTarget, root)
+	if err != nil {
+		return false, fmt.Errorf("copy %s: %w", src, err)
+	}
+	linkTarget = strings.ToLower(linkTarget)
+
+	if current, err := os.Readlink(dst); err == nil && current == linkTarget {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return false, fmt.Errorf("create dir for %s: %w", dst, err)
+	}
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("replace %s: %w", dst, err)
+	}
+	if err := os.Symlink(linkTarget, dst); err != nil {
+		return false, fmt.Errorf("link %s: %w", dst, err)
+	}
+
+	return true, nil
+}
+
+func localFileChanged(source, current fs.FileInfo, link bool) bool {
+	if link {
+		return current.Mode()&fs.ModeSymlink == 0
+	}
+	return source.Size() != current.Size() || source.ModTime().After(current.ModTime())
+}
+
+func linkFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("create dir for %s: %w", dst, err)
+	}
+
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("replace %s: %w", dst, err)
+	}
+
+	if err := os.Symlink(src, dst); err != nil {
+		return fmt.Errorf("link %s: %w", dst, err)
+	}
+
+	return nil
+}
+
+// Sync copies what changed in the local build trees of the install into
+// it and returns the install-relative paths it updated.
+func (i *Installer) Sync(ctx context.Context) ([]string, error) {
+	manifest, err := i.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	if manifest.GeosDir == "" && manifest.BaseboxDir == "" {
+		return nil, fmt.Errorf("%s: %w", i.opts.Root, ErrNotLocal)
+	}
+	return syncInstall(ctx, i.opts.Root, manifest)
+}
+
+// syncInstall mirrors the local build trees recorded in manifest into the
+// install and returns the updated install-relative paths.
+func syncInstall(ctx context.Context, installRoot string, manifest *Manifest) ([]string, error) {
+	var updated []string
+
+	if manifest.GeosDir != "" {
+		drivecDir := filepath.Join(installRoot, "drivec")
+		target, err := localGeosTarget(manifest.GeosDir, drivecDir)
+		if err != nil {
+			return nil, err
+		}
+
+		files, err := syncLocalTree(ctx, manifest.GeosDir, target, manifest.Link)
+		updated = append(updated, prefixPaths(installRoot, target, files)...)
+		if err != nil {
+			return updated, err
+		}
+	}
+
+	if manifest.BaseboxDir != "" {
+		baseboxDir := filepath.Join(installRoot, "basebox")
+		files, err := syncLocalTree(ctx, resolveBaseboxRoot(manifest.BaseboxDir), baseboxDir, manifest.Link)
+		updated = append(updated, prefixPaths(installRoot, baseboxDir, files)...)
+		if err != nil {
+			return updated, err
+		}
+	}
+
+	return updated, nil
+}
+
+func prefixPaths(installRoot, dir string, files []string) []string {
+	prefix, err := filepath.Rel(installRoot, dir)
+	if err != nil {
+		return files
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, filepath.Join(prefix, file))
+	}
+	return paths
+}
+
+// resolveLocalDir validates a --geos-dir or --basebox-dir argument.
+func resolveLocalDir(path, label string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s dir: %w", label, err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", fmt.Errorf("%s dir: %w", label, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s dir %s is not a directory", label, absPath)
+	}
+
+	return absPath, nil
+}
@@ -0,0 +1,120 @@
+package install
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const manifestName = "geoget.json"
+
+// Manifest records how an install root was created, so that updates
+// and later config edits can reproduce the same settings.
+type Manifest struct {
+	GeosTag    string        `json:"geosTag"`
+	BaseboxTag string        `json:"baseboxTag"`
+	Lang       string        `json:"lang,omitempty"`
+	Languages  []string      `json:"languages,omitempty"`
+	Basebox    Preset        `json:"basebox"`
+	Mounts     []Mount       `json:"mounts,omitempty"`
+	Keyboard   string        `json:"keyboard,omitempty"`
+	Video      string        `json:"video,omitempty"`
+	Resolution string        `json:"resolution,omitempty"`
+	Ini        []IniOverride `json:"ini,omitempty"`
+	GeosDir    string        `json:"geosDir,omitempty"`
+	BaseboxDir string        `json:"baseboxDir,omitempty"`
+	Link       bool          `json:"link,omitempty"`
+
+	GeosSource    string `json:"geosSource,omitempty"`
+	BaseboxSource string `json:"baseboxSource,omitempty"`
+}
+
+// loadManifest returns the manifest of installRoot, or nil if there is none.
+func loadManifest(installRoot string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(installRoot, manifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", manifestName, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", manifestName, err)
+	}
+
+	return &manifest, nil
+}
+
+func saveManifest(installRoot string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", manifestName, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(installRoot, manifestName), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", manifestName, err)
+	}
+
+	return nil
+}
+
+// newManifest combines the options of this run with the settings kept from
+// a previous install of the same root.
+func newManifest(opts Options, geosTag, baseboxTag string, languages []string, previous *Manifest) *Manifest {
+	manifest := &Manifest{
+		GeosTag:    geosTag,
+		BaseboxTag: baseboxTag,
+		Languages:  languages,
+		Basebox:    opts.Basebox,
+		Mounts:     mergeMounts(nil, opts.Mounts),
+		Keyboard:   opts.Keyboard,
+		Video:      opts.Video,
+		Resolution: opts.Resolution,
+		GeosDir:    opts.GeosDir,
+		BaseboxDir: opts.BaseboxDir,
+		Link:       opts.Link,
+
+		GeosSource:    sourceSpec(opts.GeosSource, GeosRepo),
+		BaseboxSource: sourceSpec(opts.BaseboxSource, BaseboxRepo),
+	}
+
+	if previous != nil {
+		manifest.Basebox = previous.Basebox.overlay(opts.Basebox)
+		manifest.Mounts = mergeMounts(previous.Mounts, opts.Mounts)
+		if opts.Keyboard == "" {
+			manifest.Keyboard = previous.Keyboard
+		}
+		if opts.Video == "" {
+			manifest.Video = previous.Video
+		}
+		if opts.Resolution == "" {
+			manifest.Resolution = previous.Resolution
+		}
+		manifest.Ini = previous.Ini
+	}
+
+	if manifest.Video == ResetSetting {
+		manifest.Video = ""
+	}
+	if manifest.Resolution == ResetSetting {
+		manifest.Resolution = ""
+	}
+
+	return manifest
+}
+
+// languages returns the installed language packages, including the single
+// "lang" of manifests written before side-by-side installs.
+func (m *Manifest) languages() []string {
+	if len(m.Languages) > 0 {
+		return m.Languages
+	}
+	if m.Lang != "" {
+		return []string{m.Lang}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package install
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// mirrorFor returns the part of the mirror at base that serves the
+// releases of repo. Mirrors use the paths of GitHub's download URLs,
+// <base>/<owner>/<repo>/releases/download/<tag>/<archive>, so one mirror
+// holds both GEOS and Basebox.
+func mirrorFor(base, repo string) (ArtifactSource, error) {
+	src, err := ParseSource(base, repo)
+	if err != nil {
+		return nil, fmt.Errorf("mirror: %w", err)
+	}
+
+	switch s := src.(type) {
+	case mirrorSource:
+		return MirrorSource(s.base + "/" + repo + "/releases/download"), nil
+	case dirSource:
+		return dirSource{dir: mirrorDir(s.dir, repo)}, nil
+	}
+	return nil, fmt.Errorf("mirror %q: expected an http(s) URL or a directory", base)
+}
+
+// sourceHealth remembers which sources failed during this run. Sources
+// that were unreachable or served a stale archive are tried after the
+// others from then on. Failures count per repo, as one directory or
+// mirror may serve GEOS and Basebox with only one of them missing.
+type sourceHealth struct {
+	mu       sync.Mutex
+	failures map[healthKey]int
+}
+
+type healthKey struct {
+	repo   string
+	source string
+}
+
+func newSourceHealth() *sourceHealth {
+	return &sourceHealth{failures: make(map[healthKey]int)}
+}
+
+// order returns the sources of repo with the healthiest first, keeping
+// the configured order among equals.
+func (h *sourceHealth) order(repo string, sources []ArtifactSource) []ArtifactSource {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ordered := append([]ArtifactSource(nil), sources...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return h.failures[healthKey{repo, ordered[i].String()}] < h.failures[healthKey{repo, ordered[j].String()}]
+	})
+	return ordered
+}
+
+func (h *sourceHealth) failed(repo string, src ArtifactSource) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures[healthKey{repo, src.String()}]++
+}
+
+// releaseListing is the asset list of a release and the source it came
+// from.
+type releaseListing struct {
+	assets []Asset
+	source ArtifactSource
+}
+
+// listRelease lists the archives of release tag of repo from the first
+// of sources that can. Sources that cannot list at all are skipped
+// without counting as failed. The error is that of the first source.
+func (i *Installer) listRelease(ctx context.Context, repo string, sources []ArtifactSource, tag string) (*releaseListing, error) {
+	var firstErr error
+
+	for _, src := range i.health.order(repo, sources) {
+		assets, err := src.Assets(ctx, tag)
+		if err == nil {
+			return &releaseListing{assets: assets, source: src}, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !errors.Is(err, ErrNoAssetList) {
+			i.health.failed(repo, src)
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return nil, firstErr
+}
+
+// fetchReleaseAsset downloads the archive called name of release tag of
+// repo, trying sources in order of health until one serves it with the
+// checksum from listing. It goes through the download cache if cache is
+// set and the listing identifies the asset. Listing is nil if the release
+// could not be listed.
+func (i *Installer) fetchReleaseAsset(ctx context.Context, repo string, sources []ArtifactSource, tag string, listing *releaseListing, name, destination string, cache bool, dl downloadOptions) error {
+	listed := Asset{Name: name}
+	var listedBy string
+	if listing != nil {
+		if asset, ok := findAsset(listing.assets, name); ok {
+			listed = asset
+			listedBy = listing.source.String()
+		}
+	}
+
+	var errs []error
+	for _, src := range i.health.order(repo, sources) {
+		asset := listed
+		if src.String() != listedBy {
+			// The URL belongs to the source that listed the asset.
+			asset.URL = ""
+		}
+
+		var err error
+		if cache && asset.ID != "" {
+			err = downloadCached(ctx, src, tag, asset, destination, dl)
+		} else {
+			err = downloadAsset(ctx, src, tag, asset, destination, dl)
+		}
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		i.health.failed(repo, src)
+		errs = append(errs, fmt.Errorf("%s: %w", src, err))
+		if len(errs) < len(sources) {
+			i.logger.Printf("Could not download %s from %s, trying the next mirror: %v\n", name, src, err)
+		}
+	}
+
+	if len(errs) == 1 {
+		return errors.Unwrap(errs[0])
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,253 @@
+package install
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const mirrorChecksumsName = "SHA256SUMS"
+
+// MirrorOptions select the releases SyncMirror copies.
+type MirrorOptions struct {
+	// Dir is the mirror directory.
+	Dir string
+	// GeosTags lists the GEOS releases, CI-latest if empty.
+	GeosTags []string
+	// BaseboxTags lists the Basebox releases. If empty, those the
+	// compatibility table pairs with the GEOS releases are copied.
+	BaseboxTags []string
+	// Lang lists the GEOS languages to copy, comma-separated, or all
+	// languages of each release if empty.
+	Lang string
+
+	// Reporter receives progress messages; nil discards them.
+	Reporter Reporter
+	// Progress receives download progress bars; nil disables them.
+	Progress io.Writer
+	// LimitRate caps the download rate in bytes per second; 0 for none.
+	LimitRate int64
+	// Segments splits large archives into that many concurrent ranged
+	// downloads, up to MaxSegments; 0 or 1 for one stream.
+	Segments int
+	// HTTP configures the client for downloads and GitHub API requests.
+	HTTP HTTPOptions
+}
+
+// SyncMirror downloads GitHub releases into a directory laid out like
+// GitHub's download URLs, <dir>/<owner>/<repo>/releases/download/<tag>/,
+// so that it can be served over HTTP and used with Options.Mirrors. Each
+// release gets an assets.json with sizes and checksums and a SHA256SUMS
+// file. Archives already up to date are kept, so syncing again only
+// fetches rebuilt CI releases.
+func SyncMirror(ctx context.Context, opts MirrorOptions) error {
+	if strings.TrimSpace(opts.Dir) == "" {
+		return errors.New("no mirror directory given")
+	}
+	if err := checkSegments(opts.Segments); err != nil {
+		return err
+	}
+	dir, err := filepath.Abs(opts.Dir)
+	if err != nil {
+		return fmt.Errorf("resolve mirror directory: %w", err)
+	}
+
+	httpClient, err := newHTTPClient(opts.HTTP)
+	if err != nil {
+		return err
+	}
+	ctx = withClient(ctx, httpClient)
+
+	logger := opts.Reporter
+	if logger == nil {
+		logger = discardReporter{}
+	}
+
+	geosTags := opts.GeosTags
+	if len(geosTags) == 0 {
+		geosTags = []string{DefaultTag}
+	}
+
+	baseboxTags := opts.BaseboxTags
+	if len(baseboxTags) == 0 {
+		compat, err := loadCompatMatrix(logger)
+		if err != nil {
+			return err
+		}
+		for _, geosTag := range geosTags {
+			paired, _, err := compat.pairBasebox(geosTag, DefaultTag, false, false)
+			if err != nil {
+				return err
+			}
+			if !containsString(baseboxTags, paired) {
+				baseboxTags = append(baseboxTags, paired)
+			}
+		}
+	}
+
+	dl := downloadOptions{progress: opts.Progress, limiter: newRateLimiter(opts.LimitRate), segments: opts.Segments}
+
+	for _, tag := range geosTags {
+		wanted := func(assets []Asset) ([]Asset, error) {
+			languages := assetLanguages(assetNames(assets))
+			if opts.Lang != "" {
+				var err error
+				if languages, err = resolveLanguages(opts.Lang, languages); err != nil {
+					return nil, err
+				}
+			}
+
+			var selected []Asset
+			for _, lang := range languages {
+				if asset, ok := findAsset(assets, geosArchiveName+lang+".zip"); ok {
+					selected = append(selected, asset)
+				}
+			}
+			return selected, nil
+		}
+
+		logger.Printf("Mirroring GEOS %s\n", tag)
+		if err := syncMirrorRelease(ctx, dir, GeosRepo, tag, wanted, logger, dl); err != nil {
+			return err
+		}
+	}
+
+	for _, tag := range baseboxTags {
+		wanted := func(assets []Asset) ([]Asset, error) {
+			asset, ok := findAsset(assets, baseboxArchiveName)
+			if !ok {
+				return nil, fmt.Errorf("release has no %s", baseboxArchiveName)
+			}
+			return []Asset{asset}, nil
+		}
+
+		logger.Printf("Mirroring Basebox %s\n", tag)
+		if err := syncMirrorRelease(ctx, dir, BaseboxRepo, tag, wanted, logger, dl); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncMirrorRelease copies the assets wanted selects from release tag of
+// repo into the mirror at dir.
+func syncMirrorRelease(ctx context.Context, dir, repo, tag string, wanted func([]Asset) ([]Asset, error), logger Reporter, dl downloadOptions) error {
+	src := GitHubSource(repo)
+
+	assets, err := src.Assets(ctx, tag)
+	if err != nil {
+		return err
+	}
+	selected, err := wanted(assets)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", repo, tag, err)
+	}
+
+	releaseDir, err := dirSource{dir: mirrorDir(dir, repo)}.releaseDir(tag)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(releaseDir, 0o755); err != nil {
+		return fmt.Errorf("create mirror dir: %w", err)
+	}
+
+	// Without a previous index every archive is downloaded again.
+	previous, _ := dirSource{dir: mirrorDir(dir, repo)}.Assets(ctx, tag)
+
+	var index []Asset
+	for _, asset := range selected {
+		path := filepath.Join(releaseDir, asset.Name)
+
+		if old, ok := findAsset(previous, asset.Name); ok && old.ID == asset.ID && old.SHA256 != "" {
+			if sum, err := fileSHA256(path); err == nil && sum == old.SHA256 {
+				logger.Printf("%s is up to date\n", asset.Name)
+				index = append(index, old)
+				continue
+			}
+		}
+
+		tmp := path + ".download"
+		err := downloadAsset(ctx, src, tag, asset, tmp, dl)
+		if err == nil {
+			asset.SHA256, err = fileSHA256(tmp)
+		}
+		if err == nil {
+			err = os.Rename(tmp, path)
+		}
+		if err != nil {
+			os.Remove(tmp)
+			return &DownloadError{Component: repo + " " + tag + " " + asset.Name, Err: err}
+		}
+
+		asset.URL = ""
+		index = append(index, asset)
+	}
+
+	// Archives an earlier sync selected, such as other languages, stay
+	// listed as long as their files are there.
+	for _, old := range previous {
+		if _, ok := findAsset(index, old.Name); ok || old.Name != filepath.Base(old.Name) {
+			continue
+		}
+		path := filepath.Join(releaseDir, old.Name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if old.SHA256 == "" {
+			if old.SHA256, err = fileSHA256(path); err != nil {
+				return err
+			}
+		}
+		index = append(index, old)
+	}
+
+	return writeMirrorIndex(releaseDir, index)
+}
+
+// mirrorDir returns where the mirror at dir keeps the releases of repo.
+func mirrorDir(dir, repo string) string {
+	return filepath.Join(dir, filepath.FromSlash(repo), "releases", "download")
+}
+
+// writeMirrorIndex writes the assets.json and SHA256SUMS of a release.
+func writeMirrorIndex(releaseDir string, assets []Asset) error {
+	data, err := json.MarshalIndent(assets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", assetIndexName, err)
+	}
+	if err := os.WriteFile(filepath.Join(releaseDir, assetIndexName), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", assetIndexName, err)
+	}
+
+	var sums strings.Builder
+	for _, asset := range assets {
+		fmt.Fprintf(&sums, "%s  %s\n", asset.SHA256, asset.Name)
+	}
+	if err := os.WriteFile(filepath.Join(releaseDir, mirrorChecksumsName), []byte(sums.String()), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", mirrorChecksumsName, err)
+	}
+
+	return nil
+}
+
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("checksum %s: %w", filepathBase(path), err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
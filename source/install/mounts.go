@@ -0,0 +1,131 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Mount types: a host folder, a floppy image or a CD image.
+const (
+	MountDir    = "dir"
+	MountFloppy = "floppy"
+	MountCDROM  = "iso"
+)
+
+// Mount is an additional DOS drive set up by the generated autoexec.
+// An empty Path removes a mount kept from a previous install.
+type Mount struct {
+	Drive string `json:"drive"`
+	Type  string `json:"type"`
+	Path  string `json:"path,omitempty"`
+}
+
+// ParseMount parses "D=/path" and checks that the host path fits the
+// mount type: a folder for dir mounts, an image file otherwise.
+func ParseMount(kind, value string) (Mount, error) {
+	drive, path, ok := strings.Cut(value, "=")
+	drive = strings.ToUpper(strings.TrimSpace(drive))
+	if !ok || len(drive) != 1 || drive[0] < 'A' || drive[0] > 'Z' {
+		return Mount{}, fmt.Errorf("expected <drive>=<path>, got %q", value)
+	}
+
+	if drive == "C" {
+		return Mount{}, fmt.Errorf("drive C: is reserved for the Ensemble install")
+	}
+
+	mount := Mount{Drive: drive, Type: kind}
+
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return mount, nil
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return Mount{}, fmt.Errorf("resolve %s: %w", path, err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return Mount{}, fmt.Errorf("mount %s: %w", drive, err)
+	}
+
+	if kind == MountDir && !info.IsDir() {
+		return Mount{}, fmt.Errorf("mount %s: %s is not a directory", drive, absPath)
+	}
+
+	if kind != MountDir && !info.Mode().IsRegular() {
+		return Mount{}, fmt.Errorf("mount %s: %s is not an image file", drive, absPath)
+	}
+
+	mount.Path = absPath
+	return mount, nil
+}
+
+// mergeMounts returns base with every drive in overrides replaced, added,
+// or, for overrides without a path, removed.
+func mergeMounts(base, overrides []Mount) []Mount {
+	merged := append([]Mount(nil), base...)
+
+	for _, override := range overrides {
+		kept := merged[:0]
+		for _, m := range merged {
+			if m.Drive != override.Drive {
+				kept = append(kept, m)
+			}
+		}
+		merged = kept
+
+		if override.Path != "" {
+			merged = append(merged, override)
+		}
+	}
+
+	return merged
+}
+
+func (m Mount) command() string {
+	drive := strings.ToLower(m.Drive)
+	switch m.Type {
+	case MountDir:
+		return fmt.Sprintf("mount %s \"%s\" -t dir", drive, m.Path)
+	default:
+		return fmt.Sprintf("imgmount %s \"%s\" -t %s", drive, m.Path, m.Type)
+	}
+}
+
+// applyMounts mounts the additional drives before the autoexec switches to C:.
+func applyMounts(config *baseboxConfig, mounts []Mount) {
+	for _, m := range mounts {
+		if m.Path != "" {
+			insertAutoexec(config, m.command())
+		}
+	}
+}
+
+// insertAutoexec adds commands to the generated autoexec right before it
+// switches to drive C:, after any commands inserted earlier.
+func insertAutoexec(config *baseboxConfig, commands ...string) {
+	autoexec := config.section(autoexecSection)
+
+	pos := -1
+	for i, line := range autoexec.lines {
+		fields := strings.Fields(strings.ToLower(line))
+		if len(fields) == 1 && fields[0] == "c:" {
+			pos = i
+			break
+		}
+		if len(fields) >= 2 && fields[0] == "mount" && fields[1] == "c" || len(fields) > 0 && fields[0] == "@echo" {
+			pos = i + 1
+		}
+	}
+	if pos < 0 {
+		pos = 0
+	}
+
+	lines := append([]string(nil), autoexec.lines[:pos]...)
+	lines = append(lines, commands...)
+	autoexec.lines = append(lines, autoexec.lines[pos:]...)
+}
@@ -0,0 +1,156 @@
+package install
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Preset holds the display and performance choices applied on top of
+// the embedded basebox.conf template. Empty fields leave the template alone.
+type Preset struct {
+	Display    string `json:"display,omitempty"`
+	CPU        string `json:"cpu,omitempty"`
+	Fullscreen *bool  `json:"fullscreen,omitempty"`
+	Window     string `json:"window,omitempty"`
+	Scaler     string `json:"scaler,omitempty"`
+}
+
+var baseboxDisplays = []string{"opengl", "texture", "surface"}
+
+// defaultPresetForArch picks settings known to work for a Basebox build.
+// The Raspberry Pi build frequently runs without a usable OpenGL stack.
+func defaultPresetForArch(arch string) Preset {
+	preset := Preset{Display: "opengl", CPU: "max"}
+	if arch == "rpi64" {
+		preset.Display = "texture"
+	}
+	return preset
+}
+
+// hostPreset returns the defaults of the Basebox build for this machine,
+// which the chosen settings are overlaid on.
+func hostPreset() Preset {
+	if archs := orderedBaseboxArchs(); len(archs) > 0 {
+		return defaultPresetForArch(archs[0])
+	}
+	return defaultPresetForArch("")
+}
+
+// overlay returns p with every field set in o taking precedence.
+func (p Preset) overlay(o Preset) Preset {
+	if o.Display != "" {
+		p.Display = o.Display
+	}
+	if o.CPU != "" {
+		p.CPU = o.CPU
+	}
+	if o.Fullscreen != nil {
+		p.Fullscreen = o.Fullscreen
+	}
+	if o.Window != "" {
+		p.Window = o.Window
+	}
+	if o.Scaler != "" {
+		p.Scaler = o.Scaler
+	}
+	return p
+}
+
+func (p Preset) validate() error {
+	if p.Display != "" && !containsString(baseboxDisplays, p.Display) {
+		return fmt.Errorf("display must be one of %s: %q", strings.Join(baseboxDisplays, ", "), p.Display)
+	}
+
+	if p.CPU != "" {
+		if _, _, err := parseCPUPreset(p.CPU); err != nil {
+			return err
+		}
+	}
+
+	if p.Window != "" {
+		if _, _, err := parseResolution(p.Window); err != nil {
+			return fmt.Errorf("window: %w", err)
+		}
+	}
+
+	if p.Scaler != "" {
+		if strings.ContainsAny(p.Scaler, " \t\r\n") {
+			return fmt.Errorf("scaler name must not contain whitespace: %q", p.Scaler)
+		}
+		if p.Display != "" && p.Display != "opengl" && p.Scaler != "none" {
+			return fmt.Errorf("scaler %q requires the opengl display", p.Scaler)
+		}
+	}
+
+	return nil
+}
+
+// apply writes the preset into a generated Basebox configuration.
+func (p Preset) apply(config *baseboxConfig) {
+	if p.Display != "" {
+		config.set("sdl", "output", p.Display)
+	}
+
+	if p.CPU != "" {
+		mode, cycles, _ := parseCPUPreset(p.CPU)
+		switch mode {
+		case "max":
+			config.set("cpu", "cycles", "max")
+			config.set("cpu", "cpu_cycles", "max")
+			config.set("cpu", "cpu_cycles_protected", "auto")
+		case "auto":
+			config.set("cpu", "cycles", "auto")
+			config.set("cpu", "cpu_cycles", "3000")
+			config.set("cpu", "cpu_cycles_protected", "max")
+		case "fixed":
+			config.set("cpu", "cycles", "fixed "+cycles)
+			config.set("cpu", "cpu_cycles", cycles)
+			config.set("cpu", "cpu_cycles_protected", cycles)
+		}
+	}
+
+	if p.Fullscreen != nil {
+		config.set("sdl", "fullscreen", fmt.Sprint(*p.Fullscreen))
+	}
+
+	if p.Window != "" {
+		config.set("sdl", "windowresolution", p.Window)
+	}
+
+	if p.Scaler != "" {
+		config.set("render", "glshader", p.Scaler)
+	}
+}
+
+// parseCPUPreset accepts "max", "auto" or "fixed:<cycles>".
+func parseCPUPreset(value string) (string, string, error) {
+	switch value {
+	case "max", "auto":
+		return value, "", nil
+	}
+
+	cycles, ok := strings.CutPrefix(value, "fixed:")
+	if !ok || !isNumeric(cycles) || strings.TrimLeft(cycles, "0") == "" {
+		return "", "", fmt.Errorf("cpu must be max, auto or fixed:<cycles>: %q", value)
+	}
+
+	return "fixed", cycles, nil
+}
+
+// parseResolution parses a WxH pair such as 1024x768.
+func parseResolution(value string) (string, string, error) {
+	width, height, ok := strings.Cut(strings.ToLower(value), "x")
+	if !ok || !isNumeric(width) || !isNumeric(height) {
+		return "", "", fmt.Errorf("expected WxH (e.g. 1024x768): %q", value)
+	}
+	return width, height, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
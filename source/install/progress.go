@@ -1,4 +1,4 @@
-package main
+package install
 
 import (
 	"fmt"
@@ -10,9 +10,11 @@ import (
 )
 
 type progressWriter struct {
+	mu         sync.Mutex
 	label      string
 	total      int64
 	written    int64
+	started    time.Time
 	lastRender time.Time
 	manager    *progressManager
 	line       int
@@ -37,13 +39,19 @@ func newProgressWriter(label string, total int64, out io.Writer) *progressWriter
 	return &progressWriter{
 		label:      label,
 		total:      total,
+		started:    time.Now(),
 		lastRender: time.Now().Add(-time.Second),
 		manager:    manager,
 		line:       manager.register(),
 	}
 }
 
+// Write counts data towards the progress. Segments of one download share
+// a progressWriter, so it is safe for concurrent use.
 func (p *progressWriter) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	n := len(data)
 	p.written += int64(n)
 
@@ -57,7 +65,9 @@ func (p *progressWriter) Write(data []byte) (int, error) {
 }
 
 func (p *progressWriter) Finish() {
+	p.mu.Lock()
 	p.render()
+	p.mu.Unlock()
 	p.manager.finish()
 }
 
@@ -66,15 +76,24 @@ func (p *progressWriter) render() {
 }
 
 func (p *progressWriter) progressText() string {
+	var text string
 	if p.total > 0 {
 		percent := float64(p.written) / float64(p.total) * 100
 		if percent > 100 {
 			percent = 100
 		}
-		return fmt.Sprintf("%3.0f%% (%s / %s)", percent, humanBytes(p.written), humanBytes(p.total))
+		text = fmt.Sprintf("%3.0f%% (%s / %s)", percent, humanBytes(p.written), humanBytes(p.total))
+	} else {
+		text = fmt.Sprintf("%s downloaded", humanBytes(p.written))
+	}
+
+	// Averaged over the whole download, so throttled downloads show the
+	// rate they actually get.
+	if elapsed := time.Since(p.started); elapsed >= 500*time.Millisecond {
+		text += fmt.Sprintf(", %s/s", humanBytes(int64(float64(p.written)/elapsed.Seconds())))
 	}
 
-	return fmt.Sprintf("%s downloaded", humanBytes(p.written))
+	return text
 }
 
 func newProgressManager(out io.Writer) *progressManager {
@@ -0,0 +1,113 @@
+package install
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// downloadOptions control how archives are downloaded.
+type downloadOptions struct {
+	// progress receives progress bars; nil disables them.
+	progress io.Writer
+	// limiter caps the combined throughput of all downloads; nil for
+	// none.
+	limiter *rateLimiter
+	// segments splits large downloads into that many ranged requests
+	// where the server supports it; 0 or 1 for a single stream.
+	segments int
+}
+
+// rateLimiter is a token bucket shared by concurrent downloads. Readers
+// take one token per byte and wait while the bucket is in debt.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a limiter for bytesPerSecond, or nil if it is not
+// positive.
+func newRateLimiter(bytesPerSecond int64) *rateLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+
+	// A small bucket keeps the rate even instead of letting a download
+	// burst for a second after each pause.
+	burst := float64(bytesPerSecond) / 8
+	if burst < 16*1024 {
+		burst = 16 * 1024
+	}
+
+	return &rateLimiter{
+		rate:   float64(bytesPerSecond),
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// wait takes n tokens and sleeps until the bucket has recovered from the
+// debt, or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens -= float64(n)
+
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reader returns r throttled by l, or r itself if l is nil.
+func (l *rateLimiter) reader(ctx context.Context, r io.Reader) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &limitedReader{ctx: ctx, r: r, limiter: l}
+}
+
+type limitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rateLimiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if max := int(r.limiter.burst); len(p) > max {
+		p = p[:max]
+	}
+
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.wait(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
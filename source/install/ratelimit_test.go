@@ -0,0 +1,83 @@
+package install
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := []struct {
+		rate  int64
+		nil   bool
+		burst float64
+	}{
+		{0, true, 0},
+		{-1, true, 0},
+		{1024, false, 16 * 1024},
+		{8 << 20, false, 1 << 20},
+	}
+
+	for _, tt := range tests {
+		l := newRateLimiter(tt.rate)
+		if (l == nil) != tt.nil {
+			t.Errorf("newRateLimiter(%d) = %v", tt.rate, l)
+			continue
+		}
+		if l != nil && l.burst != tt.burst {
+			t.Errorf("newRateLimiter(%d) burst = %.0f, want %.0f", tt.rate, l.burst, tt.burst)
+		}
+	}
+}
+
+func TestRateLimiterReader(t *testing.T) {
+	const rate = 256 * 1024
+
+	tests := []struct {
+		name    string
+		size    int
+		minTime time.Duration
+	}{
+		// The bucket starts full, so one burst goes through at once.
+		{"within burst", 32 * 1024, 0},
+		{"throttled", 32*1024 + rate/4, 200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newRateLimiter(rate)
+			start := time.Now()
+			n, err := io.Copy(io.Discard, l.reader(context.Background(), bytes.NewReader(make([]byte, tt.size))))
+			elapsed := time.Since(start)
+
+			if err != nil || n != int64(tt.size) {
+				t.Fatalf("copied %d, %v; want %d", n, err, tt.size)
+			}
+			if elapsed < tt.minTime {
+				t.Errorf("took %v, want at least %v", elapsed, tt.minTime)
+			}
+		})
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	l := newRateLimiter(1024)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := io.Copy(io.Discard, l.reader(ctx, bytes.NewReader(make([]byte, 64*1024))))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestNilRateLimiterReader(t *testing.T) {
+	r := bytes.NewReader(nil)
+	var l *rateLimiter
+	if got := l.reader(context.Background(), r); got != io.Reader(r) {
+		t.Errorf("nil limiter wrapped the reader")
+	}
+}
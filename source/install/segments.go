@@ -0,0 +1,185 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaxSegments bounds Options.Segments and MirrorOptions.Segments; more
+// parallel requests rarely help and some servers throttle clients that
+// open many.
+const MaxSegments = 16
+
+func checkSegments(segments int) error {
+	if segments < 0 || segments > MaxSegments {
+		return fmt.Errorf("segments must be between 0 and %d", MaxSegments)
+	}
+	return nil
+}
+
+// minSegmentedSize is the smallest download split into segments; below
+// it the extra requests cost more than they save.
+const minSegmentedSize = 4 << 20
+
+// downloadSegmented fetches url in dl.segments concurrent ranged requests
+// and reassembles them in destination. It reports false without error if
+// the server does not support ranges or the file is too small, leaving
+// the download to a single stream.
+func downloadSegmented(ctx context.Context, url, name, checksum, destination string, dl downloadOptions) (bool, error) {
+	size, location, err := probeRanges(ctx, url)
+	if err != nil {
+		return false, err
+	}
+	if size < minSegmentedSize {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepathDir(destination), 0o755); err != nil {
+		return true, fmt.Errorf("create download dir: %w", err)
+	}
+
+	out, err := os.Create(destination)
+	if err != nil {
+		return true, fmt.Errorf("create file: %w", err)
+	}
+	defer out.Close()
+
+	if err := out.Truncate(size); err != nil {
+		return true, fmt.Errorf("allocate %s: %w", name, err)
+	}
+
+	var bar *progressWriter
+	if dl.progress != nil {
+		bar = newProgressWriter(name, size, dl.progress)
+	}
+
+	segCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	segments := int64(dl.segments)
+	segmentSize := (size + segments - 1) / segments
+
+	// The first failure cancels the other segments, whose errors then
+	// only repeat it.
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	for n := int64(0); n < segments; n++ {
+		start := n * segmentSize
+		end := min(start+segmentSize, size)
+		if start >= end {
+			break
+		}
+
+		wg.Add(1)
+		go func(n, start, end int64) {
+			defer wg.Done()
+			if err := downloadSegment(segCtx, location, start, end, out, bar, dl.limiter); err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("segment %d of %s: %w", n+1, name, err)
+					cancel()
+				})
+			}
+		}(n, start, end)
+	}
+
+	wg.Wait()
+
+	if bar != nil {
+		bar.Finish()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return true, err
+	}
+	if firstErr != nil {
+		return true, firstErr
+	}
+
+	if checksum != "" {
+		if sum, err := fileSHA256(destination); err != nil {
+			return true, err
+		} else if !strings.EqualFold(sum, checksum) {
+			return true, &ChecksumError{Name: name, Want: checksum, Got: sum}
+		}
+	}
+
+	return true, nil
+}
+
+// probeRanges asks for the first byte of url. If the server answers with
+// a partial response, it returns the full size and the URL after
+// redirects, so the segments skip them; otherwise a size of 0.
+func probeRanges(ctx context.Context, url string) (int64, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("GET %s: %w", url, err)
+	}
+	req.Header.Set("Range", "bytes=0-0")
+
+	resp, err := client(ctx).Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("GET %s: %w", url, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, "", nil
+	}
+
+	// Content-Range: bytes 0-0/<size>
+	_, total, ok := strings.Cut(resp.Header.Get("Content-Range"), "/")
+	size, err := strconv.ParseInt(total, 10, 64)
+	if !ok || err != nil {
+		return 0, "", nil
+	}
+
+	return size, resp.Request.URL.String(), nil
+}
+
+// downloadSegment writes bytes [start, end) of url to the same offset in
+// out.
+func downloadSegment(ctx context.Context, url string, start, end int64, out *os.File, bar *progressWriter, limiter *rateLimiter) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+
+	resp, err := client(ctx).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return &httpStatusError{url: url, code: resp.StatusCode, status: resp.Status}
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-%d/", start, end-1)) {
+		return fmt.Errorf("server sent range %q", resp.Header.Get("Content-Range"))
+	}
+
+	var reader io.Reader = limiter.reader(ctx, io.LimitReader(resp.Body, end-start))
+	if bar != nil {
+		reader = io.TeeReader(reader, bar)
+	}
+
+	written, err := io.Copy(io.NewOffsetWriter(out, start), reader)
+	if err != nil {
+		return err
+	}
+	if written != end-start {
+		return fmt.Errorf("got %d bytes, expected %d", written, end-start)
+	}
+
+	return nil
+}
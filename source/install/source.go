@@ -0,0 +1,322 @@
+package install
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// GeosRepo and BaseboxRepo are the GitHub repositories the builds are
+	// released from.
+	GeosRepo    = "bluewaysw/pcgeos"
+	BaseboxRepo = "bluewaysw/pcgeos-basebox"
+
+	githubDownloadBaseURL = "https://github.com"
+
+	// assetIndexName is the optional asset list in a release directory of
+	// a mirror, needed where the directory itself cannot be listed.
+	assetIndexName = "assets.json"
+)
+
+// ErrNoAssetList is returned by sources that cannot list the archives of a
+// release. The installer then asks for the archives it expects by name.
+var ErrNoAssetList = errors.New("source cannot list release assets")
+
+// ArtifactSource serves the release archives of GEOS or Basebox. Releases
+// are found by tag; each holds archives such as pcgeos-ensemble_nc.zip.
+type ArtifactSource interface {
+	// Assets lists the archives of the release tag.
+	Assets(ctx context.Context, tag string) ([]Asset, error)
+	// Open returns the content of an archive of the release tag and its
+	// size, or -1 if unknown. The asset need not come from Assets; one
+	// with only a Name is looked up by name.
+	Open(ctx context.Context, tag string, asset Asset) (io.ReadCloser, int64, error)
+	// String returns the source in the form ParseSource accepts.
+	String() string
+}
+
+// Asset is an archive of a release.
+type Asset struct {
+	Name string `json:"name"`
+	// Size is the archive size in bytes, 0 if unknown.
+	Size int64 `json:"size,omitempty"`
+	// ID changes whenever the archive is rebuilt under the same tag. It
+	// keys the download cache; assets without one are not cached.
+	ID string `json:"id,omitempty"`
+	// URL is where the source found the archive, if it differs from the
+	// default location of the name.
+	URL string `json:"url,omitempty"`
+	// SHA256 is the hex checksum of the archive, "" if unknown. Downloads
+	// that do not match it fail.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// findAsset looks up the asset called name in assets.
+func findAsset(assets []Asset, name string) (Asset, bool) {
+	for _, asset := range assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+func assetNames(assets []Asset) []string {
+	names := make([]string, 0, len(assets))
+	for _, asset := range assets {
+		names = append(names, asset.Name)
+	}
+	return names
+}
+
+// ParseSource returns the source described by spec:
+//
+//	github               GitHub releases of repo
+//	github:owner/name    GitHub releases of another repository
+//	https://host/path    a mirror laid out as <path>/<tag>/<archive>
+//	file:///path, /path  a local directory laid out the same way
+func ParseSource(spec, repo string) (ArtifactSource, error) {
+	spec = strings.TrimSpace(spec)
+
+	switch {
+	case spec == "" || spec == "github":
+		return GitHubSource(repo), nil
+	case strings.HasPrefix(spec, "github:"):
+		other := strings.TrimPrefix(spec, "github:")
+		if strings.Count(other, "/") != 1 || strings.HasPrefix(other, "/") || strings.HasSuffix(other, "/") {
+			return nil, fmt.Errorf("source %q: expected github:owner/name", spec)
+		}
+		return GitHubSource(other), nil
+	case strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://"):
+		if _, err := url.Parse(spec); err != nil {
+			return nil, fmt.Errorf("source %q: %w", spec, err)
+		}
+		return MirrorSource(spec), nil
+	case strings.HasPrefix(spec, "file:"):
+		u, err := url.Parse(spec)
+		if err != nil {
+			return nil, fmt.Errorf("source %q: %w", spec, err)
+		}
+		if u.Host != "" && u.Host != "localhost" {
+			return nil, fmt.Errorf("source %q: file URLs must name a local path", spec)
+		}
+		return DirSource(filepath.FromSlash(u.Path))
+	case strings.Contains(spec, "://"):
+		return nil, fmt.Errorf("source %q: unsupported URL scheme", spec)
+	default:
+		return DirSource(spec)
+	}
+}
+
+// sourceOrDefault returns src, or the GitHub releases of repo if nil.
+func sourceOrDefault(src ArtifactSource, repo string) ArtifactSource {
+	if src == nil {
+		return GitHubSource(repo)
+	}
+	return src
+}
+
+// sourceSpec returns the spec of src to record in the manifest, or "" for
+// the default GitHub releases of repo.
+func sourceSpec(src ArtifactSource, repo string) string {
+	if src == nil || src.String() == GitHubSource(repo).String() {
+		return ""
+	}
+	return src.String()
+}
+
+type githubSource struct {
+	repo string
+}
+
+// GitHubSource returns the GitHub releases of repo ("owner/name"). Listing
+// uses the GitHub API; archives are downloaded from github.com, so
+// installing a known language still works when the API rate limit is hit.
+func GitHubSource(repo string) ArtifactSource {
+	return githubSource{repo: repo}
+}
+
+func (s githubSource) Assets(ctx context.Context, tag string) ([]Asset, error) {
+	release, err := fetchRelease(ctx, s.repo, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := make([]Asset, 0, len(release.Assets))
+	for _, asset := range release.Assets {
+		sha, _ := strings.CutPrefix(asset.Digest, "sha256:")
+		assets = append(assets, Asset{
+			Name:   asset.Name,
+			Size:   asset.Size,
+			ID:     strconv.FormatInt(asset.ID, 10),
+			URL:    asset.BrowserDownloadURL,
+			SHA256: sha,
+		})
+	}
+	return assets, nil
+}
+
+func (s githubSource) Open(ctx context.Context, tag string, asset Asset) (io.ReadCloser, int64, error) {
+	return httpOpen(ctx, s.assetURL(tag, asset))
+}
+
+func (s githubSource) assetURL(tag string, asset Asset) string {
+	if asset.URL != "" {
+		return asset.URL
+	}
+	return fmt.Sprintf("%s/%s/releases/download/%s/%s", githubDownloadBaseURL, s.repo, url.PathEscape(tag), url.PathEscape(asset.Name))
+}
+
+func (s githubSource) String() string {
+	return "github:" + s.repo
+}
+
+type mirrorSource struct {
+	base string
+}
+
+// MirrorSource returns a plain HTTP mirror at baseURL that serves the
+// archives of a release as <baseURL>/<tag>/<archive>. Listing needs an
+// assets.json in the release directory, a JSON array of Asset.
+func MirrorSource(baseURL string) ArtifactSource {
+	return mirrorSource{base: strings.TrimRight(baseURL, "/")}
+}
+
+func (s mirrorSource) Assets(ctx context.Context, tag string) ([]Asset, error) {
+	endpoint := s.url(tag, assetIndexName)
+
+	body, _, err := httpOpen(ctx, endpoint)
+	var status *httpStatusError
+	if errors.As(err, &status) && status.code == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", s.url(tag, ""), ErrNoAssetList)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return decodeAssetIndex(body, endpoint)
+}
+
+func (s mirrorSource) Open(ctx context.Context, tag string, asset Asset) (io.ReadCloser, int64, error) {
+	return httpOpen(ctx, s.assetURL(tag, asset))
+}
+
+func (s mirrorSource) assetURL(tag string, asset Asset) string {
+	if asset.URL != "" {
+		return asset.URL
+	}
+	return s.url(tag, asset.Name)
+}
+
+func (s mirrorSource) String() string {
+	return s.base
+}
+
+func (s mirrorSource) url(tag, name string) string {
+	return s.base + "/" + url.PathEscape(tag) + "/" + url.PathEscape(name)
+}
+
+type dirSource struct {
+	dir string
+}
+
+// DirSource returns a local directory that holds the archives of a release
+// in <dir>/<tag>/<archive>, such as a copy of a mirror or a network share.
+// An assets.json in the release directory takes precedence over listing
+// the archives.
+func DirSource(dir string) (ArtifactSource, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve source dir: %w", err)
+	}
+	return dirSource{dir: abs}, nil
+}
+
+func (s dirSource) Assets(ctx context.Context, tag string) ([]Asset, error) {
+	releaseDir, err := s.releaseDir(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	if index, err := os.Open(filepath.Join(releaseDir, assetIndexName)); err == nil {
+		defer index.Close()
+		return decodeAssetIndex(index, index.Name())
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read asset list: %w", err)
+	}
+
+	entries, err := os.ReadDir(releaseDir)
+	if err != nil {
+		return nil, fmt.Errorf("list release %s: %w", tag, err)
+	}
+
+	var assets []Asset
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".zip") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("stat %s: %w", entry.Name(), err)
+		}
+		assets = append(assets, Asset{Name: entry.Name(), Size: info.Size()})
+	}
+	sort.Slice(assets, func(i, j int) bool { return assets[i].Name < assets[j].Name })
+
+	return assets, nil
+}
+
+func (s dirSource) Open(ctx context.Context, tag string, asset Asset) (io.ReadCloser, int64, error) {
+	releaseDir, err := s.releaseDir(tag)
+	if err != nil {
+		return nil, 0, err
+	}
+	if asset.Name != filepath.Base(asset.Name) {
+		return nil, 0, fmt.Errorf("invalid asset name %q", asset.Name)
+	}
+
+	file, err := os.Open(filepath.Join(releaseDir, asset.Name))
+	if err != nil {
+		return nil, 0, fmt.Errorf("open %s: %w", asset.Name, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("stat %s: %w", asset.Name, err)
+	}
+
+	return file, info.Size(), nil
+}
+
+func (s dirSource) String() string {
+	return s.dir
+}
+
+// releaseDir returns the directory of tag, refusing tags that would
+// leave the source directory.
+func (s dirSource) releaseDir(tag string) (string, error) {
+	if tag == "" || tag == "." || tag == ".." || strings.ContainsAny(tag, `/\`) {
+		return "", fmt.Errorf("invalid release tag %q", tag)
+	}
+	return filepath.Join(s.dir, tag), nil
+}
+
+func decodeAssetIndex(r io.Reader, name string) ([]Asset, error) {
+	var assets []Asset
+	if err := json.NewDecoder(r).Decode(&assets); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", name, err)
+	}
+	return assets, nil
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package install
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// linkNotPermitted reports whether err says that the file system does not
+// allow symbolic links, as FAT does.
+func linkNotPermitted(err error) bool {
+	return errors.Is(err, fs.ErrPermission)
+}
@@ -0,0 +1,18 @@
+package install
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+)
+
+// errPrivilegeNotHeld is ERROR_PRIVILEGE_NOT_HELD, which Windows returns
+// for links created outside Developer Mode by users who are not
+// administrators.
+const errPrivilegeNotHeld syscall.Errno = 1314
+
+// linkNotPermitted reports whether err says that the system does not let
+// the user create symbolic links.
+func linkNotPermitted(err error) bool {
+	return errors.Is(err, errPrivilegeNotHeld) || errors.Is(err, fs.ErrPermission)
+}
@@ -0,0 +1,321 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// confirmInstallRoot checks that installRoot may be replaced, asking
+// confirm unless force is set.
+func confirmInstallRoot(installRoot string, force bool, confirm func(string) (bool, error)) error {
+	if installRoot == "" || installRoot == "/" || installRoot == string(filepath.Separator) {
+		return fmt.Errorf("refusing to operate on empty install root")
+	}
+
+	if _, err := os.Stat(installRoot); err == nil {
+		if force {
+			return nil
+		}
+		if confirm == nil {
+			return fmt.Errorf("%s: %w", installRoot, ErrRootExists)
+		}
+		confirmed, confirmErr := confirm(installRoot)
+		if confirmErr != nil {
+			return confirmErr
+		}
+		if !confirmed {
+			return ErrAborted
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("check install root: %w", err)
+	}
+
+	return nil
+}
+
+// swapInstallRoot moves the staged install into place. An existing
+// install root is moved aside instead of deleted, so that a failed or
+// interrupted install can put it back. It returns where the previous
+// install went, or "" if there was none. Until finishInstallRoot or
+// rollbackInstallRoot, a marker tells recoverInstallRoot that the install
+// root is not complete.
+func swapInstallRoot(installRoot, staging string) (string, error) {
+	marker := updatingPath(installRoot)
+	if err := os.WriteFile(marker, nil, 0o644); err != nil {
+		return "", fmt.Errorf("mark update in progress: %w", err)
+	}
+
+	backup := ""
+	if exists(installRoot) {
+		backup = backupPath(installRoot)
+		if err := os.RemoveAll(backup); err != nil {
+			os.Remove(marker)
+			return "", fmt.Errorf("remove old backup: %w", err)
+		}
+
+		if err := os.Rename(installRoot, backup); err != nil {
+			os.Remove(marker)
+			return "", fmt.Errorf("move existing install root aside: %w", err)
+		}
+	}
+
+	if err := os.Rename(staging, installRoot); err != nil {
+		if backup != "" {
+			if restoreErr := os.Rename(backup, installRoot); restoreErr != nil {
+				return "", fmt.Errorf("move staged install into place: %w (previous install left in %s)", err, backup)
+			}
+		}
+		os.Remove(marker)
+		return "", fmt.Errorf("move staged install into place: %w", err)
+	}
+
+	return backup, nil
+}
+
+// finishInstallRoot marks the install root complete and deletes the
+// previous install. The backup is renamed before it is deleted, so that
+// one left over by a failed or interrupted delete is never restored.
+func finishInstallRoot(installRoot, backup string) error {
+	if err := os.Remove(updatingPath(installRoot)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("mark update complete: %w", err)
+	}
+
+	if backup == "" {
+		return nil
+	}
+
+	discard := discardPath(installRoot)
+	if err := os.RemoveAll(discard); err != nil {
+		return fmt.Errorf("remove the previous install in %s: %w", discard, err)
+	}
+	if err := os.Rename(backup, discard); err != nil {
+		return fmt.Errorf("remove the previous install in %s: %w", backup, err)
+	}
+	if err := os.RemoveAll(discard); err != nil {
+		return fmt.Errorf("remove the previous install in %s: %w", discard, err)
+	}
+
+	return nil
+}
+
+// prepareStaging creates an empty staging dir for the new install next to
+// installRoot, so that it can be renamed into place, and removes one left
+// behind by an interrupted run.
+func prepareStaging(installRoot string) (string, error) {
+	staging := filepath.Join(filepath.Dir(installRoot), "."+filepath.Base(installRoot)+".staging")
+	if err := os.RemoveAll(staging); err != nil {
+		return "", fmt.Errorf("remove old staging dir: %w", err)
+	}
+	if err := os.MkdirAll(staging, 0o755); err != nil {
+		return "", fmt.Errorf("create staging dir: %w", err)
+	}
+	return staging, nil
+}
+
+// rollbackInstallRoot removes a partial install and puts the previous one
+// back in its place.
+func rollbackInstallRoot(installRoot, backup string) error {
+	if err := os.RemoveAll(installRoot); err != nil {
+		return fmt.Errorf("remove partial install: %w", err)
+	}
+
+	if backup != "" {
+		if err := os.Rename(backup, installRoot); err != nil {
+			return fmt.Errorf("restore previous install from %s: %w", backup, err)
+		}
+	}
+
+	if err := os.Remove(updatingPath(installRoot)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("clear update marker: %w", err)
+	}
+
+	return nil
+}
+
+// recoverInstallRoot puts back an install moved aside by a run that was
+// killed before it could finish or roll back, and reports whether it did.
+// A previous install left over after the update completed is deleted
+// instead.
+func recoverInstallRoot(installRoot string) (bool, error) {
+	if err := os.RemoveAll(discardPath(installRoot)); err != nil {
+		return false, fmt.Errorf("remove the previous install: %w", err)
+	}
+
+	backup := backupPath(installRoot)
+	if !exists(backup) {
+		return false, nil
+	}
+
+	if exists(installRoot) && !exists(updatingPath(installRoot)) {
+		if err := os.RemoveAll(backup); err != nil {
+			return false, fmt.Errorf("remove the previous install in %s: %w", backup, err)
+		}
+		return false, nil
+	}
+
+	if err := rollbackInstallRoot(installRoot, backup); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func backupPath(installRoot string) string {
+	return filepath.Join(filepath.Dir(installRoot), "."+filepath.Base(installRoot)+".previous")
+}
+
+// discardPath is where a previous install is deleted, out of the way of
+// recoverInstallRoot.
+func discardPath(installRoot string) string {
+	return filepath.Join(filepath.Dir(installRoot), "."+filepath.Base(installRoot)+".discard")
+}
+
+// updatingPath marks an install root that an update has not completed.
+func updatingPath(installRoot string) string {
+	return filepath.Join(filepath.Dir(installRoot), "."+filepath.Base(installRoot)+".updating")
+}
+
+func prepareInstallDirs(installRoot, drivecDir, baseboxDir string) error {
+	if installRoot == "" || installRoot == "/" || installRoot == string(filepath.Separator) {
+		return fmt.Errorf("refusing to operate on empty install root")
+	}
+
+	if err := os.MkdirAll(drivecDir, 0o755); err != nil {
+		return fmt.Errorf("create \"drive c\" dir: %w", err)
+	}
+
+	if err := os.MkdirAll(baseboxDir, 0o755); err != nil {
+		return fmt.Errorf("create basebox dir: %w", err)
+	}
+
+	return nil
+}
+
+// userFiles lists install-relative files that survive a reinstall.
+var userFiles = []string{
+	filepath.Join("basebox", baseboxUserConfigName),
+}
+
+func saveUserFiles(installRoot string) (map[string][]byte, error) {
+	saved := make(map[string][]byte)
+
+	for _, relPath := range userFiles {
+		data, err := os.ReadFile(filepath.Join(installRoot, relPath))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", relPath, err)
+		}
+		saved[relPath] = data
+	}
+
+	return saved, nil
+}
+
+func restoreUserFiles(installRoot string, saved map[string][]byte) error {
+	for relPath, data := range saved {
+		dest := filepath.Join(installRoot, relPath)
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return fmt.Errorf("create dir for %s: %w", relPath, err)
+		}
+		if err := os.WriteFile(dest, data, 0o644); err != nil {
+			return fmt.Errorf("restore %s: %w", relPath, err)
+		}
+	}
+
+	return nil
+}
+
+// IssueTag returns the release tag of the CI build for an issue number
+// such as 829 or #829, or "" for an empty input. Label names the project
+// in errors.
+func IssueTag(input, label string) (string, error) {
+	issue := strings.TrimSpace(input)
+	if issue == "" {
+		return "", nil
+	}
+
+	issue = strings.TrimPrefix(issue, "#")
+	if issue == "" {
+		return "", fmt.Errorf("%s issue number cannot be empty", label)
+	}
+
+	if !isNumeric(issue) {
+		return "", fmt.Errorf("%s issue number must be numeric: %q", label, input)
+	}
+
+	return fmt.Sprintf("CI-latest-issue-%s", issue), nil
+}
+
+func resolveBaseboxRoot(baseDir string) string {
+	candidate := filepath.Join(baseDir, baseboxArchiveRoot)
+	if exists(candidate) {
+		return candidate
+	}
+	return baseDir
+}
+
+func resolveGeosLoaderDir(baseDir string) (string, error) {
+	var found string
+
+	err := filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		if d.Name() == "loader.exe" {
+			found = filepath.Base(filepath.Dir(path))
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("search LOADER.EXE: %w", err)
+	}
+
+	if found == "" {
+		return found, fmt.Errorf("LOADER.EXE not found: %w", err)
+	}
+
+	return found, nil
+}
+
+func isNumeric(input string) bool {
+	for _, ch := range input {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return input != ""
+}
+
+func filepathDir(path string) string {
+	dir := filepath.Dir(path)
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+func filepathBase(path string) string {
+	return filepath.Base(path)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().Perm()&0o111 != 0
+}
@@ -0,0 +1,73 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+)
+
+// Verify checks that the install root holds everything a launcher needs:
+// the manifest, a Basebox executable for this machine and, per language,
+// LOADER.EXE, GEOS.INI, the Basebox config and the launcher. Problems are
+// returned together as a *VerifyError.
+func (i *Installer) Verify(ctx context.Context) error {
+	manifest, err := i.Manifest()
+	if err != nil {
+		return err
+	}
+
+	root := i.opts.Root
+	var problems []string
+
+	binary, err := detectBaseboxBinary(filepath.Join(root, "basebox"))
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	languages := manifest.languages()
+	if len(languages) == 0 {
+		languages = []string{"nc"}
+	}
+
+	for _, li := range languageInstalls(root, languages) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if _, err := resolveGeosLoaderDir(li.drivecDir); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", li.lang, err))
+		} else if _, err := findGeosIni(li.drivecDir); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", li.lang, err))
+		}
+
+		if !exists(filepath.Join(root, "basebox", li.configName)) {
+			problems = append(problems, fmt.Sprintf("%s: Basebox config %s missing", li.lang, li.configName))
+		}
+
+		if binary.arch == "" {
+			continue
+		}
+		launchers, err := launcherTemplatesForArch(binary.arch)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		for _, launcher := range launchers {
+			name := li.launcher + launcher.extension
+			if !exists(filepath.Join(root, name)) {
+				problems = append(problems, fmt.Sprintf("%s: launcher %s missing", li.lang, name))
+			}
+		}
+	}
+
+	if binary.arch != "" && runtime.GOOS != "windows" && !isExecutable(filepath.Join(root, "basebox", binary.relPath)) {
+		problems = append(problems, fmt.Sprintf("Basebox executable %s is not executable", binary.relPath))
+	}
+
+	if len(problems) > 0 {
+		return &VerifyError{Root: root, Problems: problems}
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package install
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const geosScreenCategory = "screen 0"
+
+// videoDriver describes how a GEOS video driver names its devices in
+// GEOS.INI. A nil resolutions list means the driver accepts any size.
+type videoDriver struct {
+	device      string
+	resolutions []string
+}
+
+var videoDrivers = map[string]videoDriver{
+	"vga.geo":   {device: "VGA: %sx%s 16-color", resolutions: []string{"640x480"}},
+	"vga8.geo":  {device: "VESA Compatible SuperVGA: %sx%s 256-color"},
+	"vga16.geo": {device: "VESA Compatible SuperVGA: %sx%s 64K-color"},
+	"vga24.geo": {device: "VESA Compatible SuperVGA: %sx%s 16M-color"},
+}
+
+// ResetSetting as Options.Video or Options.Resolution drops the setting
+// kept from the previous install, leaving the build's GEOS.INI default.
+const ResetSetting = "default"
+
+// normalizeVideoDriver turns "vga8" or "VGA8.GEO" into "vga8.geo".
+func normalizeVideoDriver(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name != "" && name != ResetSetting && !strings.HasSuffix(name, ".geo") {
+		name += ".geo"
+	}
+	return name
+}
+
+// videoDevice returns the GEOS.INI device string of driver at resolution.
+func videoDevice(driver, resolution string) (string, error) {
+	info, ok := videoDrivers[driver]
+	if !ok {
+		return "", fmt.Errorf("unknown device names for video driver %s; set [%s] device with the ini command or use --video %s", driver, geosScreenCategory, ResetSetting)
+	}
+
+	if info.resolutions != nil && !containsString(info.resolutions, resolution) {
+		return "", fmt.Errorf("video driver %s supports only %s", driver, strings.Join(info.resolutions, ", "))
+	}
+
+	width, height, err := parseResolution(resolution)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(info.device, width, height), nil
+}
+
+// checkVideo rejects a driver and resolution that cannot go together
+// before anything is downloaded. A driver left to GEOS.INI is only known
+// once the build is installed.
+func checkVideo(driver, resolution string) error {
+	if driver == "" {
+		return nil
+	}
+	if resolution == "" {
+		resolution = "640x480"
+	}
+	_, err := videoDevice(driver, resolution)
+	return err
+}
+
+// applyResolution prepares Basebox for a GEOS screen larger than VGA: an
+// SVGA machine with VESA modes and, unless --window says otherwise, a
+// window of the same size.
+func applyResolution(config *baseboxConfig, resolution string, preset Preset) {
+	if resolution == "" {
+		return
+	}
+
+	if resolution != "640x480" {
+		config.set("dosbox", "machine", "svga_s3")
+	}
+
+	if preset.Window == "" {
+		config.set("sdl", "windowresolution", resolution)
+	}
+}
+
+// writeGeosVideo sets the [screen 0] driver and device in GEOS.INI. The
+// driver must be part of the installed build; without --video the driver
+// already configured in GEOS.INI is kept, without --resolution it runs at
+// 640x480.
+func writeGeosVideo(drivecDir, driver, resolution string) error {
+	if driver == "" && resolution == "" {
+		return nil
+	}
+
+	if resolution == "" {
+		resolution = "640x480"
+	}
+
+	loaderDir, err := resolveGeosLoaderDir(drivecDir)
+	if err != nil {
+		return err
+	}
+
+	return editGeosIni(drivecDir, func(ini *geosIni) error {
+		if driver == "" {
+			current, _ := ini.get(geosScreenCategory, "driver")
+			driver = normalizeVideoDriver(current)
+			if driver == "" {
+				return fmt.Errorf("no video driver configured in [%s]; use --video", geosScreenCategory)
+			}
+		}
+
+		found, err := findFileFold(filepath.Join(drivecDir, loaderDir), driver)
+		if err != nil {
+			return err
+		}
+		if found == "" {
+			return fmt.Errorf("video driver %s not found in the installed build; use --video %s for the build's driver", driver, ResetSetting)
+		}
+
+		device, err := videoDevice(driver, resolution)
+		if err != nil {
+			return err
+		}
+
+		ini.set(geosScreenCategory, "driver", filepath.Base(found))
+		ini.set(geosScreenCategory, "device", device)
+		return nil
+	})
+}
@@ -0,0 +1,519 @@
+package install
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ExtractLimits guard against archives that unpack to far more than a
+// GEOS or Basebox build, such as zip bombs. Zero fields use the defaults.
+type ExtractLimits struct {
+	// MaxBytes is the most an archive may unpack to (default 4 GB).
+	MaxBytes int64
+	// MaxEntries is the most files and folders an archive may hold
+	// (default 100000).
+	MaxEntries int
+	// MaxRatio is the most a file may grow when unpacked (default 100).
+	MaxRatio float64
+}
+
+func (l ExtractLimits) withDefaults() ExtractLimits {
+	if l.MaxBytes <= 0 {
+		l.MaxBytes = 4 << 30
+	}
+	if l.MaxEntries <= 0 {
+		l.MaxEntries = 100000
+	}
+	if l.MaxRatio <= 0 {
+		l.MaxRatio = 100
+	}
+	return l
+}
+
+// Creators in zip headers whose external attributes hold Unix modes.
+const (
+	creatorUnix   = 3
+	creatorMacOSX = 19
+)
+
+// minRatioCheck is the size below which a file may exceed MaxRatio, as
+// small files of repeated bytes legitimately compress very well.
+const minRatioCheck = 1 << 20
+
+// extractOptions control how archives are extracted.
+type extractOptions struct {
+	// jobs is the number of files extracted at a time; 0 for one per CPU.
+	jobs   int
+	limits ExtractLimits
+	// logger receives warnings; nil discards them.
+	logger Reporter
+	// space is shared by the archives of an install, which are extracted
+	// at the same time; nil checks each archive on its own.
+	space *spaceReservation
+}
+
+// extractZip unpacks archivePath into destination with up to eo.jobs files
+// at a time. If root is set and the archive keeps its files in a folder of
+// that name, only that folder is unpacked, in place of the archive root.
+// The first failing entry in archive order is reported and stops the
+// entries after it; cancelling ctx stops between files. Unix modes and modification times are restored
+// from the archive, and symbolic links are recreated if they stay inside
+// destination.
+//
+// Archives exceeding eo.limits fail with ErrExtractLimit, checked against
+// the zip headers up front and against the bytes actually unpacked, and a
+// file system without room for them, next to what the other archives in
+// eo.space still have to write, fails with a *DiskSpaceError before
+// anything is written.
+func extractZip(ctx context.Context, archivePath, destination, root string, eo extractOptions) error {
+	limits := eo.limits.withDefaults()
+	logger := eo.logger
+	if logger == nil {
+		logger = discardReporter{}
+	}
+
+	if err := os.MkdirAll(destination, 0o755); err != nil {
+		return fmt.Errorf("create extraction dir: %w", err)
+	}
+
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("open zip: %w", err)
+	}
+	defer reader.Close()
+
+	if len(reader.File) > limits.MaxEntries {
+		return fmt.Errorf("%w: %d entries, at most %d allowed", ErrExtractLimit, len(reader.File), limits.MaxEntries)
+	}
+
+	layout, err := zipEntries(reader.File, destination, root)
+	if err != nil {
+		return err
+	}
+
+	total, err := checkZipHeaders(layout.files, limits)
+	if err != nil {
+		return err
+	}
+	release, err := eo.space.reserve(archivePath, destination, total)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// Directories come first, in a fixed order, so the workers only ever
+	// create files.
+	paths := make([]string, 0, len(layout.dirs))
+	for path := range layout.dirs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		// Until the final pass, folders stay writable for the files that
+		// go into them.
+		if err := os.MkdirAll(path, layout.dirs[path]|0o700); err != nil {
+			return fmt.Errorf("create dir: %w", err)
+		}
+	}
+
+	jobs := eo.jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	// budget is what the archive may still unpack to, shared by the
+	// workers.
+	var budget atomic.Int64
+	budget.Store(limits.MaxBytes)
+
+	// A failure stops the entries after it, while those before it still
+	// run, so that the error reported is that of the first failing entry
+	// in archive order, whichever worker gets to it.
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failedAt = len(layout.files)
+		firstErr error
+	)
+	stopped := func(n int) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return n > failedAt || ctx.Err() != nil
+	}
+	work := make(chan int)
+
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range work {
+				if stopped(n) {
+					continue
+				}
+				entry := layout.files[n]
+				if err := extractZipFile(entry, limits, &budget); err != nil {
+					mu.Lock()
+					if n < failedAt {
+						failedAt, firstErr = n, fmt.Errorf("%s: %w", entry.file.Name, err)
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for n := range layout.files {
+		if stopped(n) {
+			break
+		}
+		work <- n
+	}
+	close(work)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+
+	// Links come after the files, one at a time, so that none is written
+	// through a link and each is checked against the links before it.
+	for _, entry := range layout.links {
+		if err := extractZipLink(entry.file, entry.target, destination, logger); err != nil {
+			return fmt.Errorf("%s: %w", entry.file.Name, err)
+		}
+	}
+
+	// Writing into a folder changes its time, so folders get their modes
+	// and times last, deepest first.
+	for n := len(layout.dirEntries) - 1; n >= 0; n-- {
+		entry := layout.dirEntries[n]
+		if err := os.Chmod(entry.target, layout.dirs[entry.target]); err != nil {
+			return fmt.Errorf("%s: set mode: %w", entry.file.Name, err)
+		}
+		if err := os.Chtimes(entry.target, entry.file.Modified, entry.file.Modified); err != nil {
+			return fmt.Errorf("%s: set time: %w", entry.file.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// zipEntry is a file of an archive and where it is extracted to.
+type zipEntry struct {
+	file   *zip.File
+	target string
+	// executable marks a file as executable although the archive has no
+	// Unix modes for it, see needsExecutable.
+	executable bool
+}
+
+// zipLayout is what an archive unpacks to below a destination.
+type zipLayout struct {
+	files []zipEntry
+	links []zipEntry
+	// dirs are the folders to create, with their modes, including those
+	// the archive only implies.
+	dirs map[string]fs.FileMode
+	// dirEntries are the folders the archive lists, sorted by path.
+	dirEntries []zipEntry
+}
+
+// zipEntries returns what the archive unpacks to below destination. Paths
+// that would leave destination fail the whole archive.
+func zipEntries(files []*zip.File, destination, root string) (zipLayout, error) {
+	prefix := ""
+	if root != "" && hasZipDir(files, root) {
+		prefix = root + "/"
+	}
+
+	layout := zipLayout{dirs: make(map[string]fs.FileMode)}
+
+	for _, f := range files {
+		name := f.Name
+		if prefix != "" {
+			if !strings.HasPrefix(name, prefix) || name == prefix {
+				continue
+			}
+			name = strings.TrimPrefix(name, prefix)
+		}
+
+		// Prevent zip slip by ensuring the final path stays inside destination.
+		targetPath := filepath.Join(destination, name)
+		if !strings.HasPrefix(filepath.Clean(targetPath), filepath.Clean(destination)+string(filepath.Separator)) {
+			return zipLayout{}, fmt.Errorf("illegal file path in zip: %s", f.Name)
+		}
+
+		if f.FileInfo().IsDir() {
+			if mode := f.Mode().Perm(); mode != 0 {
+				layout.dirs[targetPath] = mode
+			} else {
+				layout.dirs[targetPath] = 0o755
+			}
+			layout.dirEntries = append(layout.dirEntries, zipEntry{file: f, target: targetPath})
+			continue
+		}
+
+		if parent := filepath.Dir(targetPath); layout.dirs[parent] == 0 {
+			layout.dirs[parent] = 0o755
+		}
+		if f.Mode()&fs.ModeSymlink != 0 {
+			layout.links = append(layout.links, zipEntry{file: f, target: targetPath})
+		} else {
+			layout.files = append(layout.files, zipEntry{file: f, target: targetPath, executable: needsExecutable(f, name)})
+		}
+	}
+
+	sort.Slice(layout.dirEntries, func(a, b int) bool {
+		return layout.dirEntries[a].target < layout.dirEntries[b].target
+	})
+
+	return layout, nil
+}
+
+// checkZipHeaders returns the unpacked size the zip headers declare for
+// entries, failing if it or the growth of any file exceeds limits.
+func checkZipHeaders(entries []zipEntry, limits ExtractLimits) (int64, error) {
+	var total uint64
+	for _, entry := range entries {
+		f := entry.file
+		total += f.UncompressedSize64
+		if total > uint64(limits.MaxBytes) {
+			return 0, fmt.Errorf("%w: unpacks to more than %s", ErrExtractLimit, humanBytes(limits.MaxBytes))
+		}
+		if f.UncompressedSize64 >= minRatioCheck && float64(f.UncompressedSize64) > float64(f.CompressedSize64)*limits.MaxRatio {
+			return 0, fmt.Errorf("%w: %s grows more than %.0f times when unpacked", ErrExtractLimit, f.Name, limits.MaxRatio)
+		}
+	}
+	return int64(total), nil
+}
+
+// checkFreeSpace fails with a *DiskSpaceError if the file system of
+// destination has no room for size bytes of unpacked files plus the
+// archive, which is kept until they are all written. It passes where the
+// free space cannot be determined.
+func checkFreeSpace(archivePath, destination string, size int64) error {
+	if info, err := os.Stat(archivePath); err == nil {
+		size += info.Size()
+	}
+
+	free, ok := freeSpace(destination)
+	if !ok || free >= size {
+		return nil
+	}
+	return &DiskSpaceError{Path: destination, Need: size, Free: free}
+}
+
+// spaceReservation holds the bytes that the extractions running at the
+// same time are still to write, as the free space does not show them yet.
+type spaceReservation struct {
+	mu      sync.Mutex
+	pending int64
+}
+
+// reserve checks with checkFreeSpace that size bytes fit next to those of
+// the other running extractions and holds them until release is called,
+// once the files are written. A nil r checks size on its own.
+func (r *spaceReservation) reserve(archivePath, destination string, size int64) (func(), error) {
+	if r == nil {
+		return func() {}, checkFreeSpace(archivePath, destination, size)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := checkFreeSpace(archivePath, destination, r.pending+size); err != nil {
+		return nil, err
+	}
+	r.pending += size
+
+	return func() {
+		r.mu.Lock()
+		r.pending -= size
+		r.mu.Unlock()
+	}, nil
+}
+
+// needsExecutable reports whether the file name of an archive without Unix
+// modes, such as one made on Windows or a GitHub Actions artifact, must be
+// marked executable: the Basebox binaries and shell scripts.
+func needsExecutable(f *zip.File, name string) bool {
+	switch f.CreatorVersion >> 8 {
+	case creatorUnix, creatorMacOSX:
+		return false
+	}
+
+	if strings.HasSuffix(strings.ToLower(name), ".sh") {
+		return true
+	}
+	for _, relPath := range baseboxBinaryPaths {
+		if filepath.FromSlash(name) == relPath {
+			return true
+		}
+	}
+	return false
+}
+
+// hasZipDir reports whether the archive has entries in the top-level
+// folder dir.
+func hasZipDir(files []*zip.File, dir string) bool {
+	for _, f := range files {
+		if strings.HasPrefix(f.Name, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// extractZipFile writes entry to its target, whose directory exists, with
+// its mode and time. It fails once the file grows more than limits allow or
+// the archive as a whole exceeds budget, whatever the headers claim.
+func extractZipFile(entry zipEntry, limits ExtractLimits, budget *atomic.Int64) error {
+	f, targetPath := entry.file, entry.target
+
+	mode := f.Mode().Perm()
+	if entry.executable {
+		mode |= 0o111
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("open zipped file: %w", err)
+	}
+	defer rc.Close()
+
+	out, err := os.OpenFile(targetPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("create extracted file: %w", err)
+	}
+
+	maxSize := int64(float64(f.CompressedSize64) * limits.MaxRatio)
+	if maxSize < minRatioCheck {
+		maxSize = minRatioCheck
+	}
+
+	written, err := io.Copy(out, &budgetReader{r: io.LimitReader(rc, maxSize+1), budget: budget})
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if errors.Is(err, errBudgetExceeded) {
+		return fmt.Errorf("%w: unpacks to more than %s", ErrExtractLimit, humanBytes(limits.MaxBytes))
+	}
+	if err != nil {
+		return fmt.Errorf("write extracted file: %w", err)
+	}
+	if written > maxSize {
+		return fmt.Errorf("%w: grows more than %.0f times when unpacked", ErrExtractLimit, limits.MaxRatio)
+	}
+
+	if err := os.Chtimes(targetPath, f.Modified, f.Modified); err != nil {
+		return fmt.Errorf("set time: %w", err)
+	}
+
+	return nil
+}
+
+// maxLinkTarget bounds the size of a symbolic link entry, which holds the
+// path it points to.
+const maxLinkTarget = 4096
+
+// extractZipLink creates the symbolic link f at targetPath. Links whose
+// target is absolute or resolves outside destination are rejected. Where
+// the system does not permit links, as on Windows outside Developer Mode,
+// a link to a file becomes a copy of it and other links are left out with
+// a warning.
+func extractZipLink(f *zip.File, targetPath, destination string, logger Reporter) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("open zipped link: %w", err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxLinkTarget+1))
+	if err != nil {
+		return fmt.Errorf("read link: %w", err)
+	}
+	if len(data) > maxLinkTarget {
+		return errors.New("link target too long")
+	}
+
+	linkTarget, err := safeLinkTarget(targetPath, filepath.FromSlash(string(data)), destination)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(targetPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("replace %s: %w", targetPath, err)
+	}
+	if err := os.Symlink(linkTarget, targetPath); err != nil {
+		if !linkNotPermitted(err) {
+			return fmt.Errorf("create link: %w", err)
+		}
+
+		// Links in the archives belong to the Basebox binaries of other
+		// platforms, so leaving one out does not break the install.
+		source := filepath.Join(filepath.Dir(targetPath), linkTarget)
+		if info, statErr := os.Stat(source); statErr == nil && info.Mode().IsRegular() {
+			return copyFile(source, targetPath, info.Mode().Perm())
+		}
+		logger.Printf("Skipping link %s: %v\n", f.Name, err)
+	}
+
+	return nil
+}
+
+// safeLinkTarget returns linkTarget cleaned, so that ".." only leads it,
+// if a link to it at linkPath stays inside root. The link's folder is
+// resolved through the links already created, so a chain of links cannot
+// leave root either.
+func safeLinkTarget(linkPath, linkTarget, root string) (string, error) {
+	if linkTarget == "" || filepath.IsAbs(linkTarget) || filepath.VolumeName(linkTarget) != "" {
+		return "", fmt.Errorf("link to %q leaves %s", linkTarget, root)
+	}
+	cleaned := filepath.Clean(linkTarget)
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", root, err)
+	}
+	realDir, err := filepath.EvalSymlinks(filepath.Dir(linkPath))
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", filepath.Dir(linkPath), err)
+	}
+
+	if !withinDir(realRoot, filepath.Join(realDir, cleaned)) {
+		return "", fmt.Errorf("link to %q leaves %s", linkTarget, root)
+	}
+
+	return cleaned, nil
+}
+
+var errBudgetExceeded = errors.New("extraction budget exceeded")
+
+// budgetReader takes what it reads from a byte budget shared by the files
+// of an archive and fails once it is used up.
+type budgetReader struct {
+	r      io.Reader
+	budget *atomic.Int64
+}
+
+func (r *budgetReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if r.budget.Add(-int64(n)) < 0 {
+		return n, errBudgetExceeded
+	}
+	return n, err
+}
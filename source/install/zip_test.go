@@ -0,0 +1,177 @@
+package install
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSafeLinkTarget(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "a", "b"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// up points from inside the tree to its parent, so links created
+	// through it must not be followed out of root.
+	if err := os.Symlink("..", filepath.Join(root, "a", "up")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		link   string
+		target string
+		want   string
+	}{
+		{"sibling", "a/b/link", "file", "file"},
+		{"parent inside", "a/b/link", "../../file", filepath.Join("..", "..", "file")},
+		{"cleaned", "a/b/link", "./x/../file", "file"},
+		{"leaves root", "a/b/link", "../../../file", ""},
+		{"absolute", "a/link", string(filepath.Separator) + "etc", ""},
+		{"empty", "a/link", "", ""},
+		{"through link", "a/up/link", "../../file", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := safeLinkTarget(filepath.Join(root, filepath.FromSlash(tt.link)), filepath.FromSlash(tt.target), root)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("got %q, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckZipHeaders(t *testing.T) {
+	entry := func(compressed, uncompressed uint64) zipEntry {
+		return zipEntry{file: &zip.File{FileHeader: zip.FileHeader{Name: "f", CompressedSize64: compressed, UncompressedSize64: uncompressed}}}
+	}
+	limits := ExtractLimits{MaxBytes: 10 << 20, MaxRatio: 10}.withDefaults()
+
+	tests := []struct {
+		name    string
+		entries []zipEntry
+		want    int64
+		wantErr bool
+	}{
+		{"empty", nil, 0, false},
+		{"sums sizes", []zipEntry{entry(1, 1000), entry(2, 2000)}, 3000, false},
+		{"at byte limit", []zipEntry{entry(5<<20, 5<<20), entry(5<<20, 5<<20)}, 10 << 20, false},
+		{"over byte limit", []zipEntry{entry(5<<20, 5<<20), entry(5<<20, 5<<20+1)}, 0, true},
+		{"small file may exceed ratio", []zipEntry{entry(1, minRatioCheck-1)}, minRatioCheck - 1, false},
+		{"at ratio", []zipEntry{entry(1<<20, 10<<20)}, 10 << 20, false},
+		{"over ratio", []zipEntry{entry(1<<20-1, 10<<20)}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkZipHeaders(tt.entries, limits)
+			if tt.wantErr {
+				if !errors.Is(err, ErrExtractLimit) {
+					t.Errorf("got %d, %v; want ErrExtractLimit", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %d, %v; want %d", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudgetReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		budget  int64
+		wantErr bool
+	}{
+		{"under budget", 10, 11, false},
+		{"exact budget", 10, 10, false},
+		{"over budget", 11, 10, true},
+		{"no budget", 1, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var budget atomic.Int64
+			budget.Store(tt.budget)
+
+			_, err := io.Copy(io.Discard, &budgetReader{r: bytes.NewReader(make([]byte, tt.size)), budget: &budget})
+			if got := errors.Is(err, errBudgetExceeded); got != tt.wantErr {
+				t.Errorf("got %v, want exceeded %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBudgetReaderShared(t *testing.T) {
+	var budget atomic.Int64
+	budget.Store(15)
+
+	if _, err := io.Copy(io.Discard, &budgetReader{r: strings.NewReader("0123456789"), budget: &budget}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(io.Discard, &budgetReader{r: strings.NewReader("0123456789"), budget: &budget}); !errors.Is(err, errBudgetExceeded) {
+		t.Errorf("second file: got %v, want errBudgetExceeded", err)
+	}
+}
+
+func TestExtractZipLimits(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "test.zip")
+	writeTestZip(t, archive, map[string]string{"a.txt": "aaaa", "b.txt": "bbbb", "c.txt": "cccc"})
+
+	tests := []struct {
+		name    string
+		limits  ExtractLimits
+		wantErr bool
+	}{
+		{"defaults", ExtractLimits{}, false},
+		{"entries", ExtractLimits{MaxEntries: 2}, true},
+		{"bytes", ExtractLimits{MaxBytes: 11}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := extractZip(context.Background(), archive, t.TempDir(), "", extractOptions{limits: tt.limits})
+			if tt.wantErr != errors.Is(err, ErrExtractLimit) {
+				t.Errorf("got %v, want ErrExtractLimit %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func writeTestZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, data := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
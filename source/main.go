@@ -1,168 +1,119 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"sync"
-)
+	"runtime"
+	"strings"
+	"syscall"
 
-const (
-	defaultGeosReleaseTag    = "CI-latest"
-	defaultBaseboxReleaseTag = "CI-latest"
-	geosReleaseBaseURL       = "https://github.com/bluewaysw/pcgeos/releases/download"
-	baseboxReleaseBaseURL    = "https://github.com/bluewaysw/pcgeos-basebox/releases/download"
-	geosArchiveName          = "pcgeos-ensemble_"
-	baseboxArchiveName       = "pcgeos-basebox.zip"
-	geosArchiveRoot          = "ensemble"
+	"geoget/install"
 )
 
 func main() {
-
-	var wg sync.WaitGroup
-	var err1 error
-	var err2 error
-
-	/*
-		Prepare
-	*/
-
-	installRoot, force, geosTag, baseboxTag, geosLang, err := parseInstallRootAndFlags()
-	if err != nil {
-		fatal(err)
-	}
-
-	baseboxDir := filepath.Join(installRoot, "basebox")
-	drivecDir := filepath.Join(installRoot, "drivec")
-
-	logger := log.New(os.Stdout, "[geoget] ", 0)
-
-	if err := prepareInstallRoot(installRoot, force); err != nil {
+	if err := run(); err != nil {
 		fatal(err)
 	}
+}
 
-	if err := prepareInstallDirs(installRoot, drivecDir, baseboxDir); err != nil {
-		fatal(err)
-	}
-
-	tempDir, err := os.MkdirTemp("", "geoget-*")
-	if err != nil {
-		fatal(fmt.Errorf("create temp dir: %w", err))
-	}
-	defer os.RemoveAll(tempDir)
-
-	/*
-		Download
-	*/
-
-	logger.Println("Installing in", installRoot)
-
-	geosZip := filepath.Join(tempDir, "pcgeos-ensemble.zip")
-	baseboxZip := filepath.Join(tempDir, "pcgeos-basebox.zip")
-
-	wg.Add(2)
-
-	go func() {
-		defer wg.Done()
-		logger.Println("Downloading PC/GEOS Ensemble build:", geosTag, geosLang)
-		err1 = downloadFile(buildGeosReleaseURL(geosTag, geosLang), geosZip)
-	}()
+// run executes the command line. Ctrl-C or SIGTERM cancels ctx, which
+// stops downloads and rolls an install back; a second Ctrl-C exits at once.
+func run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	go func() {
-		defer wg.Done()
-		logger.Println("Downloading Basebox:", baseboxTag)
-		err2 = downloadFile(buildBaseboxReleaseURL(baseboxTag), baseboxZip)
+		<-ctx.Done()
+		stop()
 	}()
 
-	wg.Wait()
-
-	if err1 != nil {
-		fatal(fmt.Errorf("download geos: %w", err))
-	}
-
-	if err2 != nil {
-		fatal(fmt.Errorf("download basebox: %w", err))
-	}
-
-	/*
-		Extract
-	*/
-
-	geosExtractDir := filepath.Join(tempDir, "ensemble")
-	baseboxExtractDir := filepath.Join(tempDir, "basebox")
-
-	logger.Println("Extracting Ensemble archive")
-	if err := extractZip(geosZip, geosExtractDir); err != nil {
-		fatal(fmt.Errorf("extract geos: %w", err))
-	}
-
-	logger.Println("Extracting Basebox archive")
-	if err := extractZip(baseboxZip, baseboxExtractDir); err != nil {
-		fatal(fmt.Errorf("extract basebox: %w", err))
-	}
-
-	/*
-		Copy
-	*/
-
-	logger.Printf("Installing Ensemble into %s\n", drivecDir)
-	if err := copyDir(geosExtractDir, drivecDir); err != nil {
-		fatal(fmt.Errorf("copy geos: %w", err))
-	}
-
-	baseboxSource := resolveBaseboxRoot(baseboxExtractDir)
-	logger.Printf("Installing Basebox into %s\n", baseboxDir)
-	if err := copyDir(baseboxSource, baseboxDir); err != nil {
-		fatal(fmt.Errorf("copy basebox: %w", err))
-	}
-
-	/*
-		Ensure excecutables
-	*/
-
-	if err := ensureExecutables(baseboxDir); err != nil {
-		fatal(err)
+	if len(os.Args) > 1 {
+		if command, ok := subcommands[os.Args[1]]; ok {
+			return command(ctx, os.Args[2:])
+		}
 	}
 
-	baseboxBinary, err := detectBaseboxBinary(baseboxDir)
+	opts, err := parseInstallOptions(ctx)
 	if err != nil {
-		fatal(err)
+		return err
 	}
-	logger.Printf("Using Basebox executable: %s (%s)\n", baseboxBinary.relPath, baseboxBinary.arch)
 
-	/*
-		Write config, create Launchers
-	*/
-	if err := writeBaseboxConfig(baseboxDir, drivecDir); err != nil {
-		fatal(err)
+	installer, err := install.New(opts)
+	if err != nil {
+		return err
 	}
 
-	if err := createLaunchers(installRoot, baseboxBinary.arch); err != nil {
-		fatal(err)
+	if opts.Lang == "list" {
+		geosTag, languages, err := installer.Languages(ctx)
+		if err != nil {
+			return err
+		}
+		printLanguages(geosTag, languages)
+		return nil
 	}
 
-	logger.Println("Deployment complete.")
+	return installer.Install(ctx)
 }
 
-func parseInstallRootAndFlags() (string, bool, string, string, string, error) {
-	var force bool
+func parseInstallOptions(ctx context.Context) (install.Options, error) {
+	var opts install.Options
 	var help bool
 	var geosIssue string
 	var baseboxIssue string
-	var lang string
-
-	flag.BoolVar(&force, "force", false, "overwrite existing installation without prompt")
-	flag.BoolVar(&force, "f", false, "overwrite existing installation without prompt")
+	var geosSource string
+	var baseboxSource string
+	var mirrors listFlag
+	var httpOpts install.HTTPOptions
+	var fullscreen bool
+
+	flag.BoolVar(&opts.Force, "force", false, "overwrite existing installation without prompt")
+	flag.BoolVar(&opts.Force, "f", false, "overwrite existing installation without prompt")
 	flag.BoolVar(&help, "help", false, "show this help message")
 	flag.BoolVar(&help, "h", false, "show this help message")
 	flag.StringVar(&geosIssue, "geos", "", "GEOS issue number (e.g., 829 or #829)")
 	flag.StringVar(&geosIssue, "g", "", "GEOS issue number (e.g., 829 or #829)")
 	flag.StringVar(&baseboxIssue, "basebox", "", "Basebox issue number (e.g., 13 or #13)")
 	flag.StringVar(&baseboxIssue, "b", "", "Basebox issue number (e.g., 13 or #13)")
-	flag.StringVar(&lang, "lang", "", "non-english GEOS language to install (\"gr\")")
-	flag.StringVar(&lang, "l", "", "non-english GEOS language to install (\"gr\")")
+	flag.StringVar(&opts.GeosPR, "geos-pr", "", "install the CI build of a GEOS pull request")
+	flag.StringVar(&opts.GeosRef, "geos-ref", "", "install the CI build of a GEOS commit or branch")
+	flag.StringVar(&opts.BaseboxPR, "basebox-pr", "", "install the CI build of a Basebox pull request")
+	flag.StringVar(&opts.BaseboxRef, "basebox-ref", "", "install the CI build of a Basebox commit or branch")
+	flag.StringVar(&opts.GeosDir, "geos-dir", "", "install a local PC/GEOS build tree")
+	flag.StringVar(&opts.BaseboxDir, "basebox-dir", "", "install a local Basebox build tree")
+	flag.BoolVar(&opts.Link, "link", false, "symlink local build files instead of copying them")
+	flag.StringVar(&geosSource, "geos-source", "", "where to get GEOS releases: github, github:<owner/name>, a mirror URL or a directory")
+	flag.StringVar(&baseboxSource, "basebox-source", "", "where to get Basebox releases: github, github:<owner/name>, a mirror URL or a directory")
+	flag.Var(&mirrors, "mirror", "mirror to try when a download fails (repeatable)")
+	addHTTPFlags(flag.CommandLine, &httpOpts)
+	flag.Var(rateFlag{rate: &opts.LimitRate}, "limit-rate", "cap the combined download rate (e.g., 500K or 2M)")
+	flag.BoolVar(&opts.Sequential, "sequential", false, "download one archive at a time")
+	flag.IntVar(&opts.Segments, "segments", 0, "split large downloads into this many parallel requests")
+	flag.IntVar(&opts.Jobs, "jobs", runtime.NumCPU(), "number of files to extract at a time")
+	flag.Var(sizeFlag{size: &opts.Limits.MaxBytes}, "max-unpacked", "refuse archives that unpack to more than this (default 4G)")
+	flag.IntVar(&opts.Limits.MaxEntries, "max-entries", 0, "refuse archives with more files than this (default 100000)")
+	flag.Float64Var(&opts.Limits.MaxRatio, "max-ratio", 0, "refuse archives whose files grow more than this factor (default 100)")
+	flag.BoolVar(&opts.Strict, "strict", false, "fail on GEOS/Basebox combinations known not to work")
+	flag.BoolVar(&opts.RefreshCompat, "refresh-compat", false, "download the current GEOS/Basebox compatibility table")
+	flag.StringVar(&opts.Lang, "lang", "", "GEOS language to install (e.g., gr), or \"list\"")
+	flag.StringVar(&opts.Lang, "l", "", "GEOS language to install (e.g., gr), or \"list\"")
+	flag.StringVar(&opts.Basebox.Display, "display", "", "Basebox output: opengl, texture or surface")
+	flag.StringVar(&opts.Basebox.CPU, "cpu", "", "Basebox CPU speed: max, auto or fixed:<cycles>")
+	flag.BoolVar(&fullscreen, "fullscreen", false, "start Basebox in fullscreen mode")
+	flag.StringVar(&opts.Basebox.Window, "window", "", "Basebox window size (e.g., 1280x960)")
+	flag.StringVar(&opts.Basebox.Scaler, "scaler", "", "Basebox OpenGL shader (e.g., sharp or none)")
+	flag.StringVar(&opts.Keyboard, "keyboard", "", "keyboard layout, optionally with code page (e.g., gr or gr:850)")
+	flag.StringVar(&opts.Resolution, "resolution", "", "GEOS screen resolution (e.g., 1024x768), or \"default\"")
+	flag.StringVar(&opts.Video, "video", "", "GEOS video driver (e.g., vga8.geo), or \"default\"")
+	flag.Var(mountFlag{kind: install.MountDir, mounts: &opts.Mounts}, "mount", "mount a host folder as a DOS drive (e.g., D=/path)")
+	flag.Var(mountFlag{kind: install.MountFloppy, mounts: &opts.Mounts}, "floppy", "attach a floppy image (e.g., A=disk.img)")
+	flag.Var(mountFlag{kind: install.MountCDROM, mounts: &opts.Mounts}, "cdrom", "attach a CD image (e.g., E=disk.iso)")
 
 	flag.Usage = printUsage
 	flag.Parse()
@@ -172,53 +123,197 @@ func parseInstallRootAndFlags() (string, bool, string, string, string, error) {
 		os.Exit(0)
 	}
 
-	geosTag, err := resolveIssueTag(geosIssue, defaultGeosReleaseTag, "GEOS")
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "fullscreen" {
+			opts.Basebox.Fullscreen = &fullscreen
+		}
+	})
+
+	if opts.Jobs < 1 {
+		return install.Options{}, errors.New("--jobs must be at least 1")
+	}
+	if opts.Limits.MaxEntries < 0 || opts.Limits.MaxRatio < 0 {
+		return install.Options{}, errors.New("--max-entries and --max-ratio must not be negative")
+	}
+
+	var err error
+	if opts.GeosTag, err = install.IssueTag(geosIssue, "GEOS"); err != nil {
+		return install.Options{}, err
+	}
+	if opts.BaseboxTag, err = install.IssueTag(baseboxIssue, "Basebox"); err != nil {
+		return install.Options{}, err
+	}
+	if geosSource != "" {
+		if opts.GeosSource, err = install.ParseSource(geosSource, install.GeosRepo); err != nil {
+			return install.Options{}, fmt.Errorf("--geos-source: %w", err)
+		}
+	}
+	if baseboxSource != "" {
+		if opts.BaseboxSource, err = install.ParseSource(baseboxSource, install.BaseboxRepo); err != nil {
+			return install.Options{}, fmt.Errorf("--basebox-source: %w", err)
+		}
+	}
+
+	config, err := loadUserConfig()
 	if err != nil {
-		return "", false, "", "", "", err
+		return install.Options{}, err
 	}
+	opts.Mirrors = mirrorList(mirrors, config)
+	opts.HTTP = httpOptions(httpOpts, config)
 
-	baseboxTag, err := resolveIssueTag(baseboxIssue, defaultBaseboxReleaseTag, "Basebox")
+	opts.Root, err = resolveInstallRoot(flag.Arg(0))
 	if err != nil {
-		return "", false, "", "", "", err
+		return install.Options{}, err
 	}
 
-	if lang != "gr" {
-		lang = "nc"
-	} else {
-		lang = "german"
+	if !opts.Force {
+		opts.Confirm = func(root string) (bool, error) {
+			return confirmOverwrite(ctx, root)
+		}
 	}
+	opts.Reporter = log.New(os.Stdout, "[geoget] ", 0)
+	opts.Progress = os.Stdout
+
+	return opts, nil
+}
 
+func resolveInstallRoot(arg string) (string, error) {
 	root := "geospc"
-	if arg := flag.Arg(0); arg != "" {
+	if arg != "" {
 		root = arg
 	}
 
 	if filepath.IsAbs(root) {
-		return filepath.Clean(root), force, geosTag, baseboxTag, lang, nil
+		return filepath.Clean(root), nil
 	}
 
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return "", false, "", "", "", fmt.Errorf("resolve home directory: %w", err)
+		return "", fmt.Errorf("resolve home directory: %w", err)
 	}
 
-	return filepath.Join(homeDir, root), force, geosTag, baseboxTag, lang, nil
+	return filepath.Join(homeDir, root), nil
+}
+
+func printLanguages(geosTag string, languages []string) {
+	fmt.Printf("Languages available for GEOS %s:\n", geosTag)
+	for _, lang := range languages {
+		line := "  " + lang
+		if aliases := install.LanguageAliases(lang); len(aliases) > 0 {
+			line += " (" + strings.Join(aliases, ", ") + ")"
+		}
+		fmt.Println(line)
+	}
 }
 
 func printUsage() {
 	fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] [install_root]\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(flag.CommandLine.Output(), "       %s config get <section.key> [install_root]\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(flag.CommandLine.Output(), "       %s config set <section.key> <value> [install_root]\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(flag.CommandLine.Output(), "       %s ini get|set|unset <category> <key> [value] [install_root]\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(flag.CommandLine.Output(), "       %s sync [--watch] [--interval 1s] [install_root]\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(flag.CommandLine.Output(), "       %s mirror sync [--geos <tags>] [--basebox <tags>] [--lang <langs>] [--limit-rate <rate>] [--segments <n>] <dir>\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(flag.CommandLine.Output(), "       %s mirror serve [--addr :8080] <dir>\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(flag.CommandLine.Output(), "       %s bisect --good <tag> --bad <tag> [--lang <lang>] [--reset] [scratch_root]\n", filepath.Base(os.Args[0]))
 	fmt.Fprintln(flag.CommandLine.Output())
 	fmt.Fprintln(flag.CommandLine.Output(), "Options:")
 	fmt.Fprintln(flag.CommandLine.Output(), "  -f, --force            overwrite existing installation without prompt")
 	fmt.Fprintln(flag.CommandLine.Output(), "  -g, --geos <issue>     use CI-latest-<issue> for GEOS downloads (accepts 829 or #829)")
 	fmt.Fprintln(flag.CommandLine.Output(), "  -b, --basebox <issue>  use CI-latest-<issue> for Basebox downloads (accepts 13 or #13)")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --geos-pr <n>          install the CI build of GEOS pull request <n> (needs GITHUB_TOKEN)")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --geos-ref <ref>       install the CI build of a GEOS commit or branch (needs GITHUB_TOKEN)")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --basebox-pr <n>       install the CI build of Basebox pull request <n> (needs GITHUB_TOKEN)")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --basebox-ref <ref>    install the CI build of a Basebox commit or branch (needs GITHUB_TOKEN)")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --geos-dir <path>      install a local PC/GEOS build tree instead of a release")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --basebox-dir <path>   install a local Basebox build tree instead of a release")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --link                 symlink local build files instead of copying them")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --geos-source <src>    get GEOS releases from github (default), github:<owner/name>,")
+	fmt.Fprintln(flag.CommandLine.Output(), "                         a mirror URL (<url>/<tag>/<archive>), a file:// URL or a directory")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --basebox-source <src> get Basebox releases from the same kinds of source")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --mirror <url>         mirror to try when a download fails, as made by geoget mirror sync;")
+	fmt.Fprintln(flag.CommandLine.Output(), "                         repeatable, added to \"mirrors\" in config.json")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --proxy <url>          HTTP proxy (default: HTTPS_PROXY/HTTP_PROXY, or \"proxy\" in config.json);")
+	fmt.Fprintln(flag.CommandLine.Output(), "                         hosts in NO_PROXY are reached directly")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --ca-cert <pem>        trust the CA certificates in <pem>, e.g. of a company proxy")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --token <token>        GitHub token, sent only to api.github.com (default: GITHUB_TOKEN)")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --limit-rate <rate>    cap the combined download rate in bytes per second, e.g. 500K or 2M")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --sequential           download one archive at a time instead of all at once")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --segments <n>         split large downloads into n parallel ranged requests")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --jobs <n>             extract n files at a time (default: number of CPUs)")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --max-unpacked <size>  refuse archives that unpack to more than size (default 4G)")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --max-entries <n>      refuse archives with more than n files and folders (default 100000)")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --max-ratio <n>        refuse archives whose files grow more than n times (default 100)")
 	fmt.Fprintln(flag.CommandLine.Output(), "  -h, --help             show this help message")
-	fmt.Fprintln(flag.CommandLine.Output(), "  -l, --lang <lang>      non-english GEOS language to install (only \"gr\" supported for now)")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --strict               fail instead of warn on GEOS/Basebox combinations known not to work")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --refresh-compat       download the current GEOS/Basebox compatibility table first")
+	fmt.Fprintln(flag.CommandLine.Output(), "  -l, --lang <lang>      GEOS language to install (e.g. gr, de, german); \"list\" shows what the build offers;")
+	fmt.Fprintln(flag.CommandLine.Output(), "                         several (e.g. nc,gr) install side by side with one launcher each;")
+	fmt.Fprintln(flag.CommandLine.Output(), "                         without -l, a reinstall keeps the languages of the previous install")
+	fmt.Fprintln(flag.CommandLine.Output())
+	fmt.Fprintln(flag.CommandLine.Output(), "Basebox options (kept on updates):")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --display <output>     opengl, texture or surface (default: opengl, texture on rpi64)")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --cpu <speed>          max, auto or fixed:<cycles> (default: max)")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --fullscreen           start in fullscreen mode")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --window <WxH>         window size, e.g. 1280x960")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --scaler <shader>      OpenGL shader, e.g. sharp or none")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --keyboard <layout>    keyboard layout[:codepage], e.g. gr or gr:437 (default: from --lang);")
+	fmt.Fprintln(flag.CommandLine.Output(), "                         an explicit layout also selects its GEOS keyboard driver if the build has it")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --resolution <WxH>     GEOS screen size, e.g. 1024x768 (sets Basebox and GEOS.INI);")
+	fmt.Fprintln(flag.CommandLine.Output(), "                         \"default\" drops the size kept from the last install")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --video <driver>       GEOS video driver from the installed build, e.g. vga8.geo;")
+	fmt.Fprintln(flag.CommandLine.Output(), "                         \"default\" goes back to the driver the build sets up")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --mount <D=path>       mount a host folder as drive D: (repeatable, \"D=\" removes it)")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --floppy <A=image>     attach a floppy image (repeatable)")
+	fmt.Fprintln(flag.CommandLine.Output(), "  --cdrom <E=image>      attach an ISO image (repeatable)")
 	fmt.Fprintln(flag.CommandLine.Output())
 	fmt.Fprintln(flag.CommandLine.Output(), "Arguments:")
 	fmt.Fprintln(flag.CommandLine.Output(), "  install_root           optional install root; defaults to \"geospc\" under home")
 	fmt.Fprintln(flag.CommandLine.Output())
 	fmt.Fprintln(flag.CommandLine.Output(), "Defaults:")
 	fmt.Fprintln(flag.CommandLine.Output(), "  If no issue flags are provided, CI-latest is used.")
+	fmt.Fprintln(flag.CommandLine.Output(), "  Without -b, a GEOS build that needs a specific Basebox build gets it automatically.")
+}
+
+func confirmOverwrite(ctx context.Context, installRoot string) (bool, error) {
+	fmt.Printf("Install root '%s' exists, are you really sure you want to overwrite it? [y/n]: ", installRoot)
+
+	input, err := readLine(ctx, bufio.NewReader(os.Stdin))
+	if err != nil {
+		return false, fmt.Errorf("read confirmation: %w", err)
+	}
+
+	input = strings.TrimSpace(strings.ToLower(input))
+	return input == "y" || input == "yes", nil
 }
 
+// readLine reads a line of input, giving up when ctx is cancelled.
+func readLine(ctx context.Context, reader *bufio.Reader) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+
+	lines := make(chan result, 1)
+	go func() {
+		line, err := reader.ReadString('\n')
+		lines <- result{line, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		fmt.Println()
+		return "", ctx.Err()
+	case r := <-lines:
+		return r.line, r.err
+	}
+}
+
+func fatal(err error) {
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "Interrupted")
+		os.Exit(130)
+	}
+
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
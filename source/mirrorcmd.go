@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"geoget/install"
+)
+
+func runMirrorCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: mirror sync [--geos <tags>] [--basebox <tags>] [--lang <langs>] [--limit-rate <rate>] [--segments <n>] <dir> | mirror serve [--addr :8080] <dir>")
+	}
+
+	switch args[0] {
+	case "sync":
+		return mirrorSync(ctx, args[1:])
+	case "serve":
+		return mirrorServe(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown mirror command %q", args[0])
+	}
+}
+
+func mirrorSync(ctx context.Context, args []string) error {
+	var geosTags, baseboxTags listFlag
+	var lang string
+	var limitRate int64
+	var segments int
+	var httpOpts install.HTTPOptions
+
+	flags := flag.NewFlagSet("mirror sync", flag.ContinueOnError)
+	flags.Var(&geosTags, "geos", "GEOS release tags or issue numbers, comma-separated (default: CI-latest)")
+	flags.Var(&baseboxTags, "basebox", "Basebox release tags or issue numbers (default: those matching the GEOS releases)")
+	flags.StringVar(&lang, "lang", "", "GEOS languages, comma-separated (default: all)")
+	flags.Var(rateFlag{rate: &limitRate}, "limit-rate", "cap the download rate (e.g., 500K or 2M)")
+	flags.IntVar(&segments, "segments", 0, "split large downloads into this many parallel requests")
+	addHTTPFlags(flags, &httpOpts)
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("usage: mirror sync [--geos <tags>] [--basebox <tags>] [--lang <langs>] [--limit-rate <rate>] [--segments <n>] <dir>")
+	}
+
+	config, err := loadUserConfig()
+	if err != nil {
+		return err
+	}
+	opts := install.MirrorOptions{
+		Dir:       positional[0],
+		Lang:      lang,
+		Reporter:  log.New(os.Stdout, "[geoget] ", 0),
+		Progress:  os.Stdout,
+		LimitRate: limitRate,
+		Segments:  segments,
+		HTTP:      httpOptions(httpOpts, config),
+	}
+	if opts.GeosTags, err = mirrorTags(geosTags, "GEOS"); err != nil {
+		return err
+	}
+	if opts.BaseboxTags, err = mirrorTags(baseboxTags, "Basebox"); err != nil {
+		return err
+	}
+
+	if err := install.SyncMirror(ctx, opts); err != nil {
+		return err
+	}
+
+	fmt.Printf("Mirror in %s is up to date\n", positional[0])
+	return nil
+}
+
+// mirrorTags splits comma-separated tag options and turns issue numbers
+// into their CI tags.
+func mirrorTags(values []string, label string) ([]string, error) {
+	var tags []string
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				continue
+			}
+			tag, err := releaseTag(tag, label)
+			if err != nil {
+				return nil, err
+			}
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// releaseTag turns an issue number such as 829 or #829 into its CI tag
+// and returns other values as the tag they name.
+func releaseTag(value, label string) (string, error) {
+	value = strings.TrimSpace(value)
+	if issue := strings.TrimPrefix(value, "#"); strings.Trim(issue, "0123456789") == "" {
+		return install.IssueTag(value, label)
+	}
+	return value, nil
+}
+
+func mirrorServe(ctx context.Context, args []string) error {
+	var addr string
+
+	flags := flag.NewFlagSet("mirror serve", flag.ContinueOnError)
+	flags.StringVar(&addr, "addr", ":8080", "address to listen on")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("usage: mirror serve [--addr :8080] <dir>")
+	}
+
+	dir := positional[0]
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("mirror directory %s not found", dir)
+	}
+
+	logger := log.New(os.Stdout, "[geoget] ", 0)
+	files := http.FileServer(http.Dir(dir))
+	server := &http.Server{
+		Addr:              addr,
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger.Printf("%s %s %s\n", r.RemoteAddr, r.Method, r.URL.Path)
+			files.ServeHTTP(w, r)
+		}),
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	fmt.Printf("Serving %s on %s, press Ctrl-C to stop\n", dir, addr)
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("serve mirror: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// parseInterspersed parses flags that may follow the positional arguments,
+// as in "mirror serve <dir> --addr :8080", and returns the positionals.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+
+	"geoget/install"
+)
+
+// mountFlag collects repeatable --mount, --floppy and --cdrom options.
+type mountFlag struct {
+	kind   string
+	mounts *[]install.Mount
+}
+
+func (f mountFlag) String() string {
+	if f.mounts == nil {
+		return ""
+	}
+
+	var values []string
+	for _, m := range *f.mounts {
+		if m.Type == f.kind {
+			values = append(values, m.Drive+"="+m.Path)
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+func (f mountFlag) Set(value string) error {
+	mount, err := install.ParseMount(f.kind, value)
+	if err != nil {
+		return err
+	}
+
+	// Keep removals around so they still apply to the previous manifest.
+	kept := (*f.mounts)[:0]
+	for _, m := range *f.mounts {
+		if m.Drive != mount.Drive {
+			kept = append(kept, m)
+		}
+	}
+	*f.mounts = append(kept, mount)
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// rateFlag parses --limit-rate values such as 500K, 2M or 1.5M, in bytes
+// per second with binary units.
+type rateFlag struct {
+	rate *int64
+}
+
+func (f rateFlag) String() string {
+	if f.rate == nil || *f.rate == 0 {
+		return ""
+	}
+	return strconv.FormatInt(*f.rate, 10)
+}
+
+func (f rateFlag) Set(value string) error {
+	rate, ok := parseBytes(value)
+	if !ok {
+		return fmt.Errorf("invalid rate %q, expected e.g. 500K or 2M", value)
+	}
+
+	*f.rate = rate
+	return nil
+}
+
+// sizeFlag parses sizes such as 512M or 4G, with binary units.
+type sizeFlag struct {
+	size *int64
+}
+
+func (f sizeFlag) String() string {
+	if f.size == nil || *f.size == 0 {
+		return ""
+	}
+	return strconv.FormatInt(*f.size, 10)
+}
+
+func (f sizeFlag) Set(value string) error {
+	size, ok := parseBytes(value)
+	if !ok {
+		return fmt.Errorf("invalid size %q, expected e.g. 512M or 4G", value)
+	}
+
+	*f.size = size
+	return nil
+}
+
+// parseBytes parses a positive number of bytes with an optional K, M or G
+// suffix and an optional B.
+func parseBytes(value string) (int64, bool) {
+	number := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(value)), "B")
+
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(number, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(number, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(number, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		number = number[:len(number)-1]
+	}
+
+	parsed, err := strconv.ParseFloat(number, 64)
+	if err != nil || parsed <= 0 {
+		return 0, false
+	}
+
+	return int64(parsed * multiplier), true
+}
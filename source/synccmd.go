@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"geoget/install"
+)
+
+func runSyncCommand(ctx context.Context, args []string) error {
+	var watch bool
+	var interval time.Duration
+
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	flags.BoolVar(&watch, "watch", false, "keep polling the local build and sync every change")
+	flags.DurationVar(&interval, "interval", time.Second, "polling interval for --watch")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return errors.New("usage: sync [--watch] [--interval 1s] [install_root]")
+	}
+	if interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+
+	installer, err := openInstaller(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	manifest, err := installer.Manifest()
+	if err != nil {
+		return err
+	}
+	if manifest.GeosDir == "" && manifest.BaseboxDir == "" {
+		return fmt.Errorf("%s was not installed from a local build (--geos-dir/--basebox-dir)", installer.Root())
+	}
+
+	if watch {
+		return watchInstall(ctx, installer, manifest, interval)
+	}
+
+	updated, err := installer.Sync(ctx)
+	printSynced(updated)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d file(s) synced\n", len(updated))
+	return nil
+}
+
+// watchInstall polls the local build until interrupted. Sync errors, e.g.
+// a file still being written by the build, are reported and retried on
+// the next round.
+func watchInstall(ctx context.Context, installer *install.Installer, manifest *install.Manifest, interval time.Duration) error {
+	fmt.Printf("Watching %s, press Ctrl-C to stop\n", watchedDirs(manifest))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		updated, err := installer.Sync(ctx)
+		printSynced(updated)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func printSynced(updated []string) {
+	for _, rel := range updated {
+		fmt.Printf("%s synced %s\n", time.Now().Format("15:04:05"), rel)
+	}
+}
+
+func watchedDirs(manifest *install.Manifest) string {
+	var dirs []string
+	for _, dir := range []string{manifest.GeosDir, manifest.BaseboxDir} {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return strings.Join(dirs, " and ")
+}
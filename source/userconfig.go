@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const userConfigName = "config.json"
+
+// userConfig holds geoget's own settings, read from config.json in
+// geoget's folder of the user config directory.
+type userConfig struct {
+	// Mirrors are tried in order when a release archive cannot be
+	// downloaded from its source; --mirror adds to them.
+	Mirrors []string `json:"mirrors,omitempty"`
+	// Proxy and CACert are used where --proxy and --ca-cert are not
+	// given.
+	Proxy  string `json:"proxy,omitempty"`
+	CACert string `json:"caCert,omitempty"`
+}
+
+func userConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve config directory: %w", err)
+	}
+	return filepath.Join(dir, "geoget", userConfigName), nil
+}
+
+// loadUserConfig returns the user config, empty if there is none.
+func loadUserConfig() (*userConfig, error) {
+	path, err := userConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var config userConfig
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// listFlag collects a repeatable string option.
+type listFlag []string
+
+func (f *listFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *listFlag) Set(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return errors.New("value cannot be empty")
+	}
+	*f = append(*f, value)
+	return nil
+}
+
+// mirrorList puts the mirrors given on the command line before those of
+// the user config, dropping duplicates.
+func mirrorList(flagged []string, config *userConfig) []string {
+	var mirrors []string
+	for _, mirror := range append(append([]string(nil), flagged...), config.Mirrors...) {
+		mirror = strings.TrimRight(strings.TrimSpace(mirror), "/")
+		if mirror != "" && !slices.Contains(mirrors, mirror) {
+			mirrors = append(mirrors, mirror)
+		}
+	}
+	return mirrors
+}